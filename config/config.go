@@ -3,6 +3,7 @@ package config
 import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
+	"strings"
 	"time"
 )
 
@@ -14,8 +15,56 @@ type Configuration struct {
 	ServerAddress          string        `mapstructure:"SERVER_ADDRESS"`
 	TokenSymmetricKey      string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration    time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	RefreshTokenDuration   time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	MFAEncryptionKey       string        `mapstructure:"MFA_ENCRYPTION_KEY"`
 	FirebaseCredentialJSON string        `mapstructure:"FIREBASE_CREDENTIAL_JSON"`
 	FirebaseBucketName     string        `mapstructure:"FIREBASE_BUCKET_NAME"`
+	SiteTitle              string        `mapstructure:"SITE_TITLE"`
+	SiteURL                string        `mapstructure:"SITE_URL"`
+	VerificationStore      string        `mapstructure:"VERIFICATION_STORE"`
+	RedisAddress           string        `mapstructure:"REDIS_ADDRESS"`
+	RedisPassword          string        `mapstructure:"REDIS_PASSWORD"`
+	RedisDB                int           `mapstructure:"REDIS_DB"`
+	Mailer                 string        `mapstructure:"MAILER"`
+	MailFrom               string        `mapstructure:"MAIL_FROM"`
+	MailOutboxDir          string        `mapstructure:"MAIL_OUTBOX_DIR"`
+	SMTPHost               string        `mapstructure:"SMTP_HOST"`
+	SMTPPort               int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername           string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword           string        `mapstructure:"SMTP_PASSWORD"`
+	OIDCProviders          string        `mapstructure:"OIDC_PROVIDERS"`
+	// AccountDeletionGracePeriod is how long a user can cancel the deletion of their account.
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+}
+
+// OIDCProvider is an OpenID Connect provider named in OIDC_PROVIDERS.
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+}
+
+// OIDCProviderList returns the providers named in OIDC_PROVIDERS, separated by
+// commas, each one is configured by OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID
+// and OIDC_<NAME>_CLIENT_SECRET.
+func (c Configuration) OIDCProviderList() []OIDCProvider {
+	providers := []OIDCProvider{}
+	for _, name := range strings.Split(c.OIDCProviders, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		providers = append(providers, OIDCProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+		})
+	}
+	return providers
 }
 
 func LoadConfig(path string) {
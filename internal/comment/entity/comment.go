@@ -0,0 +1,84 @@
+package entity
+
+import (
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+// Status is the moderation state of a comment.
+type Status string
+
+const (
+	StatusPending  Status = "pending"
+	StatusApproved Status = "approved"
+	StatusSpam     Status = "spam"
+	StatusRemoved  Status = "removed"
+)
+
+var ErrInvalidStatus = errors.New("invalid comment status")
+
+// Comment represents a comment table in the database.
+type Comment struct {
+	ID          string
+	UserID      string
+	PostID      string
+	ParentID    string
+	Content     string
+	Status      Status
+	CreatedDate time.Time
+	LastUpdated time.Time
+}
+
+// CreateComment creates a new pending comment, or a reply when parentID is not empty.
+func CreateComment(userID, postID, parentID, content string) *Comment {
+	now := time.Now()
+	return &Comment{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		PostID:      postID,
+		ParentID:    parentID,
+		Content:     content,
+		Status:      StatusPending,
+		CreatedDate: now,
+		LastUpdated: now,
+	}
+}
+
+// Edit changes the content, the comment has to be moderated again.
+func (c *Comment) Edit(content string) {
+	c.Content = content
+	c.Status = StatusPending
+	c.LastUpdated = time.Now()
+}
+
+// Moderate changes the moderation state of the comment.
+func (c *Comment) Moderate(status Status) error {
+	switch status {
+	case StatusPending, StatusApproved, StatusSpam, StatusRemoved:
+	default:
+		return ErrInvalidStatus
+	}
+
+	c.Status = status
+	c.LastUpdated = time.Now()
+	return nil
+}
+
+// Remove empties the comment and hides it, its row is kept
+// so that the replies to it stay in their thread.
+func (c *Comment) Remove() {
+	c.Content = ""
+	c.Status = StatusRemoved
+	c.LastUpdated = time.Now()
+}
+
+// IsDeleted reports whether the comment has been emptied by Remove.
+func (c *Comment) IsDeleted() bool {
+	return c.Status == StatusRemoved && c.Content == ""
+}
+
+// IsWrittenBy reports whether the comment belongs to the given user.
+func (c *Comment) IsWrittenBy(userID string) bool {
+	return c.UserID == userID
+}
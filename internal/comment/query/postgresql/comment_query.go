@@ -0,0 +1,118 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/comment/query"
+	"github.com/SemmiDev/blog/internal/comment/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const commentColumns = `comments.id, comments.post_id, COALESCE(comments.parent_id, ''), COALESCE(comments.user_id, ''), COALESCE(users.nickname, ''),
+	comments.content, comments.status, comments.created_at, comments.updated_at`
+
+type CommentQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewCommentQueryPostgresql(DB *pgxpool.Pool) *CommentQueryPostgresql {
+	return &CommentQueryPostgresql{DB: DB}
+}
+
+func (q CommentQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		comment := storage.Comment{}
+		err := q.DB.QueryRow(ctx, `SELECT `+commentColumns+` FROM comments
+			LEFT JOIN users ON users.id = comments.user_id
+			WHERE comments.id = $1`, id).Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.ParentID,
+			&comment.UserID,
+			&comment.Nickname,
+			&comment.Content,
+			&comment.Status,
+			&comment.CreatedDate,
+			&comment.LastUpdated,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: comment}
+	}()
+
+	return result
+}
+
+// FindByPost returns the comments of a post with one of the given statuses, oldest first.
+func (q CommentQueryPostgresql) FindByPost(ctx context.Context, postID string, statuses ...string) <-chan query.Result {
+	return q.findMany(ctx, `SELECT `+commentColumns+` FROM comments
+		LEFT JOIN users ON users.id = comments.user_id
+		WHERE comments.post_id = $1 AND comments.status = ANY($2)
+		ORDER BY comments.created_at`, postID, statuses)
+}
+
+// FindPendingByPostAuthor returns the comments waiting for moderation
+// on every post written by the author.
+func (q CommentQueryPostgresql) FindPendingByPostAuthor(ctx context.Context, authorID string) <-chan query.Result {
+	return q.findMany(ctx, `SELECT `+commentColumns+` FROM comments
+		LEFT JOIN users ON users.id = comments.user_id
+		JOIN posts ON posts.id = comments.post_id
+		WHERE posts.author_id = $1 AND comments.status = 'pending'
+		ORDER BY comments.created_at`, authorID)
+}
+
+func (q CommentQueryPostgresql) findMany(ctx context.Context, sql string, args ...interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, sql, args...)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		comments := []storage.Comment{}
+		for rows.Next() {
+			comment := storage.Comment{}
+			err := rows.Scan(
+				&comment.ID,
+				&comment.PostID,
+				&comment.ParentID,
+				&comment.UserID,
+				&comment.Nickname,
+				&comment.Content,
+				&comment.Status,
+				&comment.CreatedDate,
+				&comment.LastUpdated,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			comments = append(comments, comment)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: comments}
+	}()
+
+	return result
+}
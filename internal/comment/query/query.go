@@ -0,0 +1,21 @@
+package query
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotFound is returned when the requested comment does not exist.
+var ErrNotFound = errors.New("comment not found")
+
+type CommentQuery interface {
+	FindByID(ctx context.Context, id string) <-chan Result
+	// FindByPost returns the comments of a post with one of the given statuses.
+	FindByPost(ctx context.Context, postID string, statuses ...string) <-chan Result
+	FindPendingByPostAuthor(ctx context.Context, authorID string) <-chan Result
+}
+
+type Result struct {
+	Result interface{}
+	Error  error
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/comment/entity"
+)
+
+type CommentCommand interface {
+	CommentSaver
+	CommentUpdater
+}
+
+type CommentSaver interface {
+	Save(ctx context.Context, arg *entity.Comment) <-chan error
+}
+
+type CommentUpdater interface {
+	UpdateContent(ctx context.Context, arg *entity.Comment) <-chan error
+	UpdateStatus(ctx context.Context, arg *entity.Comment) <-chan error
+}
@@ -0,0 +1,59 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/comment/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type CommentCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewCommentCommandPostgresql(DB *pgxpool.Pool) *CommentCommandPostgresql {
+	return &CommentCommandPostgresql{DB: DB}
+}
+
+func (c *CommentCommandPostgresql) Save(ctx context.Context, arg *entity.Comment) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := c.DB.Exec(ctx, `INSERT INTO comments
+			(id, user_id, post_id, parent_id, content, status, created_at, updated_at)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)`,
+			arg.ID, arg.UserID, arg.PostID, arg.ParentID, arg.Content, arg.Status, arg.CreatedDate, arg.LastUpdated)
+		result <- err
+	}()
+
+	return result
+}
+
+func (c *CommentCommandPostgresql) UpdateContent(ctx context.Context, arg *entity.Comment) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := c.DB.Exec(ctx, `UPDATE comments SET content = $2, status = $3, updated_at = $4 WHERE id = $1`,
+			arg.ID, arg.Content, arg.Status, arg.LastUpdated)
+		result <- err
+	}()
+
+	return result
+}
+
+func (c *CommentCommandPostgresql) UpdateStatus(ctx context.Context, arg *entity.Comment) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := c.DB.Exec(ctx, `UPDATE comments SET status = $2, updated_at = $3 WHERE id = $1`,
+			arg.ID, arg.Status, arg.LastUpdated)
+		result <- err
+	}()
+
+	return result
+}
@@ -0,0 +1,139 @@
+package server
+
+import (
+	queryPostgresql "github.com/SemmiDev/blog/internal/comment/query/postgresql"
+	commandPostgresql "github.com/SemmiDev/blog/internal/comment/repository/postgresql"
+	"github.com/SemmiDev/blog/internal/comment/service"
+	postQueryPostgresql "github.com/SemmiDev/blog/internal/post/query/postgresql"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	userQueryPostgresql "github.com/SemmiDev/blog/internal/user/query/postgresql"
+	userServer "github.com/SemmiDev/blog/internal/user/server"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"net/http"
+)
+
+// CommentServer is the struct that contains CommentService.
+// it will be used to interact with the service.
+type CommentServer struct {
+	CommentService service.CommentService
+}
+
+// NewCommentServer creates a new CommentServer.
+func NewCommentServer(db *pgxpool.Pool) (*CommentServer, error) {
+	commentServiceImpl := &service.CommentServiceImpl{
+		CommentQuery:   queryPostgresql.NewCommentQueryPostgresql(db),
+		CommentCommand: commandPostgresql.NewCommentCommandPostgresql(db),
+		PostQuery:      postQueryPostgresql.NewPostQueryPostgresql(db),
+		UserQuery:      userQueryPostgresql.NewUserQueryPostgresql(db),
+	}
+
+	return &CommentServer{CommentService: commentServiceImpl}, nil
+}
+
+// Mount mounts the comment server to the fiber app.
+// authMiddleware guards the routes that need an authenticated user.
+func (s *CommentServer) Mount(r fiber.Router, authMiddleware fiber.Handler) {
+	r.Get("/posts/:postID", s.FindCommentsHandler)
+	r.Post("/posts/:postID", authMiddleware, s.CreateCommentHandler)
+
+	r.Get("/moderation", authMiddleware, s.FindPendingCommentsHandler)
+	r.Put("/:id", authMiddleware, s.EditCommentHandler)
+	r.Delete("/:id", authMiddleware, s.DeleteCommentHandler)
+	r.Put("/:id/moderate", authMiddleware, s.ModerateCommentHandler)
+}
+
+// FindCommentsHandler returns the approved comments of a post as threads.
+func (s *CommentServer) FindCommentsHandler(c *fiber.Ctx) error {
+	comments, err := s.CommentService.FindComments(c.Context(), c.Params("postID"))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": comments,
+	})
+}
+
+// CreateCommentHandler comments a post.
+// if parent_id is not empty, the comment is a reply to that comment.
+func (s *CommentServer) CreateCommentHandler(c *fiber.Ctx) error {
+	content := c.FormValue("content")
+	parentID := c.FormValue("parent_id")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	comment, err := s.CommentService.CreateComment(c.Context(), c.Params("postID"), parentID, content, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"data": comment,
+	})
+}
+
+// FindPendingCommentsHandler returns the comments waiting for moderation
+// on the posts of the authenticated user.
+func (s *CommentServer) FindPendingCommentsHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	comments, err := s.CommentService.FindPendingComments(c.Context(), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": comments,
+	})
+}
+
+// EditCommentHandler changes the content of a comment of the authenticated user.
+func (s *CommentServer) EditCommentHandler(c *fiber.Ctx) error {
+	content := c.FormValue("content")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	comment, err := s.CommentService.EditComment(c.Context(), c.Params("id"), content, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": comment,
+	})
+}
+
+// DeleteCommentHandler deletes a comment of the authenticated user.
+func (s *CommentServer) DeleteCommentHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	err := s.CommentService.DeleteComment(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ModerateCommentHandler approves, rejects as spam or removes a comment
+// on a post of the authenticated user.
+func (s *CommentServer) ModerateCommentHandler(c *fiber.Ctx) error {
+	status := c.FormValue("status")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	comment, err := s.CommentService.ModerateComment(c.Context(), c.Params("id"), status, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": comment,
+	})
+}
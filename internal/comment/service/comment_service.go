@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/comment/entity"
+	"github.com/SemmiDev/blog/internal/comment/query"
+	"github.com/SemmiDev/blog/internal/comment/repository"
+	"github.com/SemmiDev/blog/internal/comment/storage"
+	postQuery "github.com/SemmiDev/blog/internal/post/query"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	userQuery "github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"unicode/utf8"
+)
+
+// maxContentLength is the maximum length of a comment.
+const maxContentLength = 5000
+
+// CommentServiceImpl is a struct that implements CommentService interface.
+type CommentServiceImpl struct {
+	CommentQuery   query.CommentQuery
+	CommentCommand repository.CommentCommand
+	PostQuery      postQuery.PostQuery
+	UserQuery      userQuery.UserQuery
+}
+
+// FindComments returns the approved comments of a published post as threads,
+// the removed comments are only kept, emptied, to hold the replies to them.
+func (s *CommentServiceImpl) FindComments(ctx context.Context, postID string) ([]*storage.Comment, error) {
+	_, err := s.findPublishedPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-s.CommentQuery.FindByPost(ctx, postID, string(entity.StatusApproved), string(entity.StatusRemoved))
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	comments, ok := result.Result.([]storage.Comment)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	// the content of the removed comments, and who wrote them, are not shown.
+	for i := range comments {
+		if comments[i].Status == string(entity.StatusRemoved) {
+			comments[i].Content = ""
+			comments[i].UserID = ""
+			comments[i].Nickname = ""
+		}
+	}
+
+	err = renderComments(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return pruneRemoved(buildThreads(comments)), nil
+}
+
+// FindPendingComments returns the moderation queue of the posts written by the user.
+func (s *CommentServiceImpl) FindPendingComments(ctx context.Context, email string) ([]storage.Comment, error) {
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-s.CommentQuery.FindPendingByPostAuthor(ctx, user.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	comments, ok := result.Result.([]storage.Comment)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	err = renderComments(comments)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// CreateComment comments a published post, or replies to a comment when parentID is set.
+// comments of the post author are approved right away, the others wait for moderation.
+func (s *CommentServiceImpl) CreateComment(ctx context.Context, postID, parentID, content, email string) (storage.Comment, error) {
+	err := validateContent(content)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	post, err := s.findPublishedPost(ctx, postID)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	if parentID != "" {
+		parent, err := s.FindCommentByID(ctx, parentID)
+		if err != nil {
+			return storage.Comment{}, err
+		}
+		if parent.PostID != post.ID {
+			return storage.Comment{}, NewErr(ErrNotFoundCode, "parent_id")
+		}
+		// the replies to a comment that is not shown would not be shown either,
+		// only its author can see it and answer it.
+		if parent.Status != entity.StatusApproved && !parent.IsWrittenBy(user.ID) {
+			return storage.Comment{}, NewErr(ErrNotFoundCode, "parent_id")
+		}
+	}
+
+	comment := entity.CreateComment(user.ID, post.ID, parentID, content)
+	if post.AuthorID == user.ID {
+		_ = comment.Moderate(entity.StatusApproved)
+	}
+
+	err = <-s.CommentCommand.Save(ctx, comment)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	return toStorage(comment, user.Nickname), nil
+}
+
+// EditComment changes the content of a comment, only its author is allowed to do so.
+func (s *CommentServiceImpl) EditComment(ctx context.Context, id, content, email string) (storage.Comment, error) {
+	err := validateContent(content)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	comment, err := s.FindCommentByID(ctx, id)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+	if comment.IsDeleted() {
+		return storage.Comment{}, NewErr(ErrNotFoundCode, "comment")
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	if !comment.IsWrittenBy(user.ID) {
+		return storage.Comment{}, NewErr(ErrForbiddenCode, "comment")
+	}
+
+	post, err := s.findPublishedPost(ctx, comment.PostID)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	comment.Edit(content)
+	if post.AuthorID == user.ID {
+		_ = comment.Moderate(entity.StatusApproved)
+	}
+
+	err = <-s.CommentCommand.UpdateContent(ctx, &comment)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	return toStorage(&comment, user.Nickname), nil
+}
+
+// DeleteComment empties and removes a comment, only its author and the users
+// who moderate every comment are allowed to do so. the replies to it are kept.
+func (s *CommentServiceImpl) DeleteComment(ctx context.Context, id, email string) error {
+	comment, err := s.FindCommentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if comment.IsDeleted() {
+		return NewErr(ErrNotFoundCode, "comment")
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return err
+	}
+
+	if !comment.IsWrittenBy(user.ID) && !user.Role.Can(rbac.PermissionModerateComments) {
+		return NewErr(ErrForbiddenCode, "comment")
+	}
+
+	comment.Remove()
+	err = <-s.CommentCommand.UpdateContent(ctx, &comment)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ModerateComment changes the moderation state of a comment,
+// only the author of the commented post and the users who moderate
+// every comment are allowed to do so.
+func (s *CommentServiceImpl) ModerateComment(ctx context.Context, id, status, email string) (storage.Comment, error) {
+	comment, err := s.FindCommentByID(ctx, id)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+	if comment.IsDeleted() {
+		return storage.Comment{}, NewErr(ErrNotFoundCode, "comment")
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	post, err := s.findPublishedPost(ctx, comment.PostID)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	if post.AuthorID != user.ID && !user.Role.Can(rbac.PermissionModerateComments) {
+		return storage.Comment{}, NewErr(ErrForbiddenCode, "comment")
+	}
+
+	err = comment.Moderate(entity.Status(status))
+	if err != nil {
+		return storage.Comment{}, NewErr(ErrInvalidStatusTransitionCode, "status")
+	}
+
+	err = <-s.CommentCommand.UpdateStatus(ctx, &comment)
+	if err != nil {
+		return storage.Comment{}, err
+	}
+
+	result := <-s.CommentQuery.FindByID(ctx, comment.ID)
+	if result.Error != nil {
+		return storage.Comment{}, result.Error
+	}
+
+	moderated, ok := result.Result.(storage.Comment)
+	if !ok {
+		return storage.Comment{}, errors.New("helper type assertion")
+	}
+
+	return moderated, nil
+}
+
+// validateContent validates the content of a comment.
+func validateContent(content string) error {
+	if content == "" {
+		return NewErr(ErrContentEmptyCode, "content")
+	}
+	if utf8.RuneCountInString(content) > maxContentLength {
+		return NewErr(ErrFieldTooLongCode, "content")
+	}
+	return nil
+}
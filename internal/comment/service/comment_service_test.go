@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/comment/entity"
+	"github.com/SemmiDev/blog/internal/comment/query"
+	"github.com/SemmiDev/blog/internal/comment/storage"
+	postQuery "github.com/SemmiDev/blog/internal/post/query"
+	postStorage "github.com/SemmiDev/blog/internal/post/storage"
+	userQuery "github.com/SemmiDev/blog/internal/user/query"
+	userStorage "github.com/SemmiDev/blog/internal/user/storage"
+	"testing"
+)
+
+// comments is a CommentQuery and CommentCommand that keeps the comments in a map.
+type comments struct {
+	rows  map[string]*entity.Comment
+	users map[string]userStorage.User
+}
+
+func (c *comments) toStorage(comment *entity.Comment) storage.Comment {
+	return toStorage(comment, c.users[comment.UserID].Nickname)
+}
+
+func (c *comments) FindByID(ctx context.Context, id string) <-chan query.Result {
+	result := make(chan query.Result, 1)
+	comment, ok := c.rows[id]
+	if !ok {
+		result <- query.Result{Error: query.ErrNotFound}
+		return result
+	}
+	result <- query.Result{Result: c.toStorage(comment)}
+	return result
+}
+
+func (c *comments) FindByPost(ctx context.Context, postID string, statuses ...string) <-chan query.Result {
+	found := []storage.Comment{}
+	for _, comment := range c.rows {
+		for _, status := range statuses {
+			if comment.PostID == postID && string(comment.Status) == status {
+				found = append(found, c.toStorage(comment))
+			}
+		}
+	}
+	result := make(chan query.Result, 1)
+	result <- query.Result{Result: found}
+	return result
+}
+
+func (c *comments) FindPendingByPostAuthor(ctx context.Context, authorID string) <-chan query.Result {
+	result := make(chan query.Result, 1)
+	result <- query.Result{Result: []storage.Comment{}}
+	return result
+}
+
+func (c *comments) Save(ctx context.Context, arg *entity.Comment) <-chan error {
+	comment := *arg
+	c.rows[arg.ID] = &comment
+	result := make(chan error, 1)
+	result <- nil
+	return result
+}
+
+func (c *comments) UpdateContent(ctx context.Context, arg *entity.Comment) <-chan error {
+	return c.Save(ctx, arg)
+}
+
+func (c *comments) UpdateStatus(ctx context.Context, arg *entity.Comment) <-chan error {
+	return c.Save(ctx, arg)
+}
+
+// posts is a PostQuery that only finds posts by id.
+type posts struct {
+	postQuery.PostQuery
+	rows map[string]postStorage.Post
+}
+
+func (p posts) FindByID(ctx context.Context, id string) <-chan postQuery.Result {
+	result := make(chan postQuery.Result, 1)
+	post, ok := p.rows[id]
+	if !ok {
+		result <- postQuery.Result{Error: postQuery.ErrNotFound}
+		return result
+	}
+	result <- postQuery.Result{Result: post}
+	return result
+}
+
+// users is a UserQuery that only finds users by email.
+type users struct {
+	userQuery.UserQuery
+	rows map[string]userStorage.User
+}
+
+func (u users) FindByEmail(ctx context.Context, email string) <-chan userQuery.Result {
+	result := make(chan userQuery.Result, 1)
+	for _, user := range u.rows {
+		if user.Email == email {
+			result <- userQuery.Result{Result: user}
+			return result
+		}
+	}
+	result <- userQuery.Result{Error: userQuery.ErrAccountNotFound}
+	return result
+}
+
+func TestDeleteCommentKeepsReplies(t *testing.T) {
+	ctx := context.Background()
+	accounts := map[string]userStorage.User{
+		"author":    {ID: "author", Email: "author@example.com", Nickname: "author"},
+		"commenter": {ID: "commenter", Email: "commenter@example.com", Nickname: "commenter"},
+		"replier":   {ID: "replier", Email: "replier@example.com", Nickname: "replier"},
+	}
+	store := &comments{rows: map[string]*entity.Comment{}, users: accounts}
+	s := &CommentServiceImpl{
+		CommentQuery:   store,
+		CommentCommand: store,
+		PostQuery:      posts{rows: map[string]postStorage.Post{"post": {ID: "post", AuthorID: "author", IsPublished: true}}},
+		UserQuery:      users{rows: accounts},
+	}
+
+	parent := entity.CreateComment("commenter", "post", "", "first")
+	parent.Status = entity.StatusApproved
+	reply := entity.CreateComment("replier", "post", parent.ID, "a reply")
+	reply.Status = entity.StatusApproved
+	<-store.Save(ctx, parent)
+	<-store.Save(ctx, reply)
+
+	err := s.DeleteComment(ctx, parent.ID, "commenter@example.com")
+	if err != nil {
+		t.Fatalf("DeleteComment() error = %v", err)
+	}
+
+	deleted := store.rows[parent.ID]
+	if deleted.Status != entity.StatusRemoved || deleted.Content != "" {
+		t.Errorf("deleted comment = %q %q, want removed and empty", deleted.Status, deleted.Content)
+	}
+	if kept, ok := store.rows[reply.ID]; !ok || kept.Content != "a reply" {
+		t.Fatalf("reply of another user is gone")
+	}
+
+	threads, err := s.FindComments(ctx, "post")
+	if err != nil {
+		t.Fatalf("FindComments() error = %v", err)
+	}
+	if len(threads) != 1 {
+		t.Fatalf("got %d threads, want 1", len(threads))
+	}
+	if threads[0].ID != parent.ID || threads[0].Content != "" || threads[0].Nickname != "" {
+		t.Errorf("thread = %+v, want the emptied parent", threads[0])
+	}
+	if len(threads[0].Replies) != 1 || threads[0].Replies[0].ID != reply.ID {
+		t.Errorf("replies = %+v, want the reply", threads[0].Replies)
+	}
+
+	// once its last reply is gone, the emptied comment is not shown anymore.
+	err = s.DeleteComment(ctx, reply.ID, "replier@example.com")
+	if err != nil {
+		t.Fatalf("DeleteComment() reply error = %v", err)
+	}
+	threads, err = s.FindComments(ctx, "post")
+	if err != nil {
+		t.Fatalf("FindComments() error = %v", err)
+	}
+	if len(threads) != 0 {
+		t.Errorf("got %d threads, want none", len(threads))
+	}
+}
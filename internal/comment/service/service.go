@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/comment/entity"
+	"github.com/SemmiDev/blog/internal/comment/query"
+	"github.com/SemmiDev/blog/internal/comment/storage"
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	postQuery "github.com/SemmiDev/blog/internal/post/query"
+	postStorage "github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	userStorage "github.com/SemmiDev/blog/internal/user/storage"
+)
+
+// CommentService is a service for managing comments of posts.
+type CommentService interface {
+	FindComments(ctx context.Context, postID string) ([]*storage.Comment, error)
+	FindPendingComments(ctx context.Context, email string) ([]storage.Comment, error)
+	CreateComment(ctx context.Context, postID, parentID, content, email string) (storage.Comment, error)
+	EditComment(ctx context.Context, id, content, email string) (storage.Comment, error)
+	DeleteComment(ctx context.Context, id, email string) error
+	ModerateComment(ctx context.Context, id, status, email string) (storage.Comment, error)
+}
+
+// FindCommentByID returns a comment by id.
+func (s CommentServiceImpl) FindCommentByID(ctx context.Context, id string) (entity.Comment, error) {
+	result := <-s.CommentQuery.FindByID(ctx, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrNotFound) {
+			return entity.Comment{}, NewErr(ErrNotFoundCode, "comment")
+		}
+		return entity.Comment{}, result.Error
+	}
+
+	comment, ok := result.Result.(storage.Comment)
+	if !ok {
+		return entity.Comment{}, errors.New("helper type assertion")
+	}
+
+	commentResult := entity.Comment{
+		ID:          comment.ID,
+		UserID:      comment.UserID,
+		PostID:      comment.PostID,
+		ParentID:    comment.ParentID,
+		Content:     comment.Content,
+		Status:      entity.Status(comment.Status),
+		CreatedDate: comment.CreatedDate,
+		LastUpdated: comment.LastUpdated,
+	}
+
+	return commentResult, nil
+}
+
+// findPublishedPost returns the post the comments belong to.
+func (s CommentServiceImpl) findPublishedPost(ctx context.Context, postID string) (postStorage.Post, error) {
+	result := <-s.PostQuery.FindByID(ctx, postID)
+	if result.Error != nil {
+		if errors.Is(result.Error, postQuery.ErrNotFound) {
+			return postStorage.Post{}, NewErr(ErrNotFoundCode, "post")
+		}
+		return postStorage.Post{}, result.Error
+	}
+
+	post, ok := result.Result.(postStorage.Post)
+	if !ok {
+		return postStorage.Post{}, errors.New("helper type assertion")
+	}
+
+	if !post.IsPublished {
+		return postStorage.Post{}, NewErr(ErrNotFoundCode, "post")
+	}
+
+	return post, nil
+}
+
+// findUserByEmail returns the user who owns the token email.
+func (s CommentServiceImpl) findUserByEmail(ctx context.Context, email string) (userStorage.User, error) {
+	result := <-s.UserQuery.FindByEmail(ctx, email)
+	if result.Error != nil {
+		return userStorage.User{}, result.Error
+	}
+
+	user, ok := result.Result.(userStorage.User)
+	if !ok {
+		return userStorage.User{}, errors.New("helper type assertion")
+	}
+
+	return user, nil
+}
+
+// toStorage converts the comment entity to the response structure.
+func toStorage(comment *entity.Comment, nickname string) storage.Comment {
+	return storage.Comment{
+		ID:          comment.ID,
+		PostID:      comment.PostID,
+		ParentID:    comment.ParentID,
+		UserID:      comment.UserID,
+		Nickname:    nickname,
+		Content:     comment.Content,
+		Status:      string(comment.Status),
+		CreatedDate: comment.CreatedDate,
+		LastUpdated: comment.LastUpdated,
+	}
+}
+
+// renderComments renders the markdown content of the comments to html.
+func renderComments(comments []storage.Comment) error {
+	for i := range comments {
+		document, err := markdown.Render(comments[i].Content)
+		if err != nil {
+			return err
+		}
+		comments[i].ContentHTML = document.HTML
+	}
+	return nil
+}
+
+// buildThreads nests the replies under their parent.
+// replies whose parent is not part of the list are dropped,
+// so a hidden comment hides its whole thread.
+func buildThreads(comments []storage.Comment) []*storage.Comment {
+	nodes := make(map[string]*storage.Comment, len(comments))
+	for i := range comments {
+		nodes[comments[i].ID] = &comments[i]
+	}
+
+	threads := []*storage.Comment{}
+	for i := range comments {
+		comment := &comments[i]
+		if comment.ParentID == "" {
+			threads = append(threads, comment)
+			continue
+		}
+		if parent, ok := nodes[comment.ParentID]; ok {
+			parent.Replies = append(parent.Replies, comment)
+		}
+	}
+
+	return threads
+}
+
+// pruneRemoved drops the removed comments that have no reply left to hold.
+func pruneRemoved(threads []*storage.Comment) []*storage.Comment {
+	kept := []*storage.Comment{}
+	for _, comment := range threads {
+		comment.Replies = pruneRemoved(comment.Replies)
+		if comment.Status == string(entity.StatusRemoved) && len(comment.Replies) == 0 {
+			continue
+		}
+		kept = append(kept, comment)
+	}
+	return kept
+}
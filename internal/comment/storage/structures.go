@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"time"
+)
+
+// Comment will be used as response for comments,
+// replies are nested under their parent.
+type Comment struct {
+	ID          string     `json:"id"`
+	PostID      string     `json:"post_id"`
+	ParentID    string     `json:"parent_id,omitempty"`
+	UserID      string     `json:"user_id"`
+	Nickname    string     `json:"nickname"`
+	Content     string     `json:"content"`
+	ContentHTML string     `json:"content_html,omitempty"`
+	Status      string     `json:"status"`
+	CreatedDate time.Time  `json:"created_at"`
+	LastUpdated time.Time  `json:"updated_at"`
+	Replies     []*Comment `json:"replies,omitempty"`
+}
@@ -0,0 +1,63 @@
+// Package encryption seals the secrets stored in the database with AES-GCM.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of the key, which selects AES-256.
+const KeySize = 32
+
+// ErrMalformed is returned when the ciphertext is too short to have been sealed.
+var ErrMalformed = errors.New("malformed ciphertext")
+
+// Cipher encrypts and decrypts secrets with a single key.
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// New creates a new Cipher, the key must be exactly KeySize characters.
+func New(key string) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("invalid key size: must be exactly %d characters", KeySize)
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals the plaintext, the random nonce is prepended to the result.
+// the additional data is authenticated but not stored, it binds the ciphertext
+// to its owner: the same additional data is needed to decrypt it.
+func (c *Cipher) Encrypt(plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Decrypt opens a ciphertext sealed by Encrypt with the same additional data.
+func (c *Cipher) Decrypt(ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, sealed, additionalData)
+}
@@ -0,0 +1,121 @@
+package encryption
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+const testKey = "abcdefghijklmnopqrstuvwxyz012345"
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		wantErr bool
+	}{
+		{name: "key of KeySize", key: testKey, wantErr: false},
+		{name: "short key", key: testKey[:16], wantErr: true},
+		{name: "long key", key: testKey + "6", wantErr: true},
+		{name: "empty key", key: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	c, err := New(testKey)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		plaintext []byte
+		owner     []byte
+	}{
+		{name: "secret", plaintext: []byte("JBSWY3DPEHPK3PXP"), owner: []byte("user-1")},
+		{name: "empty plaintext", plaintext: []byte{}, owner: []byte("user-1")},
+		{name: "no additional data", plaintext: []byte("JBSWY3DPEHPK3PXP"), owner: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := c.Encrypt(tt.plaintext, tt.owner)
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			opened, err := c.Decrypt(sealed, tt.owner)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(opened, tt.plaintext) {
+				t.Errorf("Decrypt() = %q, want %q", opened, tt.plaintext)
+			}
+		})
+	}
+}
+
+func TestEncryptNonce(t *testing.T) {
+	c, err := New(testKey)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	first, _ := c.Encrypt([]byte("secret"), nil)
+	second, _ := c.Encrypt([]byte("secret"), nil)
+	if bytes.Equal(first, second) {
+		t.Error("the same plaintext has been sealed to the same ciphertext")
+	}
+}
+
+func TestDecryptRefused(t *testing.T) {
+	c, err := New(testKey)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	other, err := New("012345abcdefghijklmnopqrstuvwxyz")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	sealed, err := c.Encrypt([]byte("secret"), []byte("user-1"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	tests := []struct {
+		name       string
+		cipher     *Cipher
+		ciphertext []byte
+		owner      []byte
+		want       error
+	}{
+		{name: "other owner", cipher: c, ciphertext: sealed, owner: []byte("user-2")},
+		{name: "other key", cipher: other, ciphertext: sealed, owner: []byte("user-1")},
+		{name: "tampered", cipher: c, ciphertext: tampered, owner: []byte("user-1")},
+		{name: "too short", cipher: c, ciphertext: sealed[:4], owner: []byte("user-1"), want: ErrMalformed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.cipher.Decrypt(tt.ciphertext, tt.owner)
+			if err == nil {
+				t.Fatal("Decrypt() error = nil, want an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Decrypt() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
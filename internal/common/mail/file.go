@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes the emails to an outbox directory as .eml files
+// instead of sending them, it is meant for local development and tests.
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a new FileMailer writing to dir.
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send writes the message to a new file of the outbox.
+func (m *FileMailer) Send(ctx context.Context, message Message) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	msg, err := message.Bytes(m.from)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(m.dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	name := time.Now().Format("20060102T150405") + "-" + uuid.NewString() + ".eml"
+	return os.WriteFile(filepath.Join(m.dir, name), msg, 0o644)
+}
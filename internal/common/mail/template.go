@@ -0,0 +1,63 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	netMail "net/mail"
+	"strings"
+	textTemplate "text/template"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	htmlTemplates = htmlTemplate.Must(htmlTemplate.ParseFS(templateFS, "templates/*.html"))
+	textTemplates = textTemplate.Must(textTemplate.ParseFS(templateFS, "templates/*.txt"))
+)
+
+// Compose renders the templates of the kind to a message for the recipient.
+// every kind has a <kind>.txt template, which defines the "subject"
+// template too, and a <kind>.html template.
+func Compose(kind, to string, data interface{}) (Message, error) {
+	text := textTemplates.Lookup(kind + ".txt")
+	html := htmlTemplates.Lookup(kind + ".html")
+	if text == nil || html == nil {
+		return Message{}, fmt.Errorf("mail: unknown kind %q", kind)
+	}
+
+	var subject, textBody, htmlBody bytes.Buffer
+
+	err := text.ExecuteTemplate(&subject, kind+".subject", data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	err = text.Execute(&textBody, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	err = html.Execute(&htmlBody, data)
+	if err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:      to,
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    textBody.String(),
+		HTML:    htmlBody.String(),
+	}, nil
+}
+
+// address returns the bare address of from, which may have a display name.
+func address(from string) string {
+	parsed, err := netMail.ParseAddress(from)
+	if err != nil {
+		return from
+	}
+	return parsed.Address
+}
@@ -0,0 +1,108 @@
+package markdown
+
+import (
+	"bytes"
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"html"
+	"regexp"
+)
+
+// Heading is an entry of the table of contents.
+type Heading struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// Document is a markdown source rendered to sanitized html.
+type Document struct {
+	HTML string    `json:"html"`
+	TOC  []Heading `json:"toc"`
+}
+
+var (
+	// renderer converts GitHub flavored markdown to html.
+	// raw html in the source is omitted, headings get an id
+	// so that they can be linked from the table of contents.
+	renderer = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	)
+
+	// policy is the allowlist of elements and attributes that
+	// survive the sanitization, everything else is stripped.
+	policy = newPolicy()
+
+	// strictPolicy strips every element, it is used for plain text fields.
+	strictPolicy = bluemonday.StrictPolicy()
+
+	// highlightPolicy keeps only the <mark> elements of search highlights.
+	highlightPolicy = bluemonday.NewPolicy().AllowElements("mark")
+)
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("id").Matching(regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+#-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	return p
+}
+
+// Render renders the markdown source to sanitized html
+// and collects its headings as a table of contents.
+func Render(source string) (Document, error) {
+	src := []byte(source)
+	doc := renderer.Parser().Parse(text.NewReader(src))
+
+	toc := []Heading{}
+	err := ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		heading, ok := n.(*ast.Heading)
+		if !ok || !entering {
+			return ast.WalkContinue, nil
+		}
+
+		id, _ := heading.AttributeString("id")
+		if b, ok := id.([]byte); ok {
+			toc = append(toc, Heading{
+				Level: heading.Level,
+				ID:    string(b),
+				Text:  string(heading.Text(src)),
+			})
+		}
+		return ast.WalkSkipChildren, nil
+	})
+	if err != nil {
+		return Document{}, err
+	}
+
+	var buf bytes.Buffer
+	err = renderer.Renderer().Render(&buf, src, doc)
+	if err != nil {
+		return Document{}, err
+	}
+
+	return Document{HTML: Sanitize(buf.String()), TOC: toc}, nil
+}
+
+// Sanitize removes every element and attribute that is not allowlisted.
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
+
+// HasMarkup reports whether the text contains an html element, it is used
+// to keep the plain text fields plain. the entities are plain text.
+func HasMarkup(text string) bool {
+	return html.UnescapeString(strictPolicy.Sanitize(text)) != html.UnescapeString(text)
+}
+
+// SanitizeHighlight removes every html element except <mark>,
+// it is used for search snippets highlighted by the database.
+func SanitizeHighlight(text string) string {
+	return highlightPolicy.Sanitize(text)
+}
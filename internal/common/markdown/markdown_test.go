@@ -0,0 +1,28 @@
+package markdown
+
+import "testing"
+
+func TestHasMarkup(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{text: "", want: false},
+		{text: "Tom & Jerry's", want: false},
+		{text: `a "quoted" bio`, want: false},
+		{text: "1 < 2 and 3 > 2", want: false},
+		{text: "I <3 Go", want: false},
+		{text: "&amp; as typed", want: false},
+		{text: "<b>bold</b>", want: true},
+		{text: `<img src=x onerror=alert(1)>`, want: true},
+		{text: "hello <script>alert(1)</script>", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := HasMarkup(tt.text); got != tt.want {
+				t.Errorf("HasMarkup(%q) = %v, want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
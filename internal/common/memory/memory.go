@@ -2,8 +2,22 @@ package memory
 
 import "time"
 
+// Store is a key value store whose entries expire after exp,
+// a zero exp never expires. Get returns nil for a missing key.
+// Storage keeps the entries in the process, PostgresStorage and
+// RedisStorage share them between the instances of the app.
 type Store interface {
 	Get(key string) ([]byte, error)
 	Set(key string, val []byte, exp time.Duration) error
 	Delete(key string) error
+	// Increment atomically adds one to the counter of the key, a new
+	// counter starts at one and expires exp after it has been started.
+	Increment(key string, exp time.Duration) (Counter, error)
+}
+
+// Counter is the value of a counter after it has been incremented.
+type Counter struct {
+	Count int64
+	// ResetIn is the time left until the counter expires.
+	ResetIn time.Duration
 }
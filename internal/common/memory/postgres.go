@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/logger"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+// PostgresStorage is a Store backed by the verification_codes table.
+type PostgresStorage struct {
+	db         *pgxpool.Pool
+	gcInterval time.Duration
+	done       chan struct{}
+}
+
+// NewPostgres creates a new PostgresStorage, the expired entries
+// are removed in the background.
+func NewPostgres(db *pgxpool.Pool) *PostgresStorage {
+	store := &PostgresStorage{
+		db:         db,
+		gcInterval: time.Minute,
+		done:       make(chan struct{}),
+	}
+	go store.gc()
+	return store
+}
+
+func (s *PostgresStorage) Get(key string) ([]byte, error) {
+	if len(key) <= 0 {
+		return nil, nil
+	}
+
+	var val []byte
+	err := s.db.QueryRow(context.Background(), `SELECT value FROM verification_codes
+		WHERE key = $1 AND (expired_at IS NULL OR expired_at > $2)`, key, time.Now()).Scan(&val)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (s *PostgresStorage) Set(key string, val []byte, exp time.Duration) error {
+	if len(key) <= 0 || len(val) <= 0 {
+		return nil
+	}
+
+	var expiredAt *time.Time
+	if exp != 0 {
+		at := time.Now().Add(exp)
+		expiredAt = &at
+	}
+
+	_, err := s.db.Exec(context.Background(), `INSERT INTO verification_codes (key, value, expired_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expired_at = EXCLUDED.expired_at`,
+		key, val, expiredAt)
+	return err
+}
+
+// Increment keeps the counter as decimal text in the value column.
+func (s *PostgresStorage) Increment(key string, exp time.Duration) (Counter, error) {
+	now := time.Now()
+
+	var count int64
+	var resetIn float64
+	err := s.db.QueryRow(context.Background(), `INSERT INTO verification_codes AS codes (key, value, expired_at)
+		VALUES ($1, '1', $2)
+		ON CONFLICT (key) DO UPDATE SET
+			value = CASE WHEN codes.expired_at <= $3 THEN '1'
+				ELSE convert_to((convert_from(codes.value, 'UTF8')::BIGINT + 1)::TEXT, 'UTF8') END,
+			expired_at = CASE WHEN codes.expired_at <= $3 THEN EXCLUDED.expired_at
+				ELSE codes.expired_at END
+		RETURNING convert_from(value, 'UTF8')::BIGINT, EXTRACT(EPOCH FROM expired_at - $3)::FLOAT8`,
+		key, now.Add(exp), now).Scan(&count, &resetIn)
+	if err != nil {
+		return Counter{}, err
+	}
+
+	return Counter{Count: count, ResetIn: time.Duration(resetIn * float64(time.Second))}, nil
+}
+
+func (s *PostgresStorage) Delete(key string) error {
+	if len(key) <= 0 {
+		return nil
+	}
+
+	_, err := s.db.Exec(context.Background(), `DELETE FROM verification_codes WHERE key = $1`, key)
+	return err
+}
+
+func (s *PostgresStorage) Reset() error {
+	_, err := s.db.Exec(context.Background(), `DELETE FROM verification_codes`)
+	return err
+}
+
+func (s *PostgresStorage) Close() error {
+	s.done <- struct{}{}
+	return nil
+}
+
+func (s *PostgresStorage) gc() {
+	ticker := time.NewTicker(s.gcInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case t := <-ticker.C:
+			_, err := s.db.Exec(context.Background(), `DELETE FROM verification_codes WHERE expired_at <= $1`, t)
+			if err != nil {
+				logger.Log.Error().Interface("verification codes gc", err.Error()).Send()
+			}
+		}
+	}
+}
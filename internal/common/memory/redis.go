@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+// RedisStorage is a Store backed by a server speaking the Redis protocol.
+// its keys are prefixed, so that it can share a database with other apps.
+type RedisStorage struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis creates a new RedisStorage connected to addr.
+func NewRedis(addr, password string, db int, prefix string) *RedisStorage {
+	return &RedisStorage{
+		client: redis.NewClient(&redis.Options{
+			Addr:     addr,
+			Password: password,
+			DB:       db,
+		}),
+		prefix: prefix,
+	}
+}
+
+func (s *RedisStorage) Get(key string) ([]byte, error) {
+	if len(key) <= 0 {
+		return nil, nil
+	}
+
+	val, err := s.client.Get(context.Background(), s.prefix+key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return val, nil
+}
+
+func (s *RedisStorage) Set(key string, val []byte, exp time.Duration) error {
+	if len(key) <= 0 || len(val) <= 0 {
+		return nil
+	}
+
+	return s.client.Set(context.Background(), s.prefix+key, val, exp).Err()
+}
+
+// Increment creates the counter with its expiry and increments it in one transaction,
+// so that a counter can not be left without an expiry.
+func (s *RedisStorage) Increment(key string, exp time.Duration) (Counter, error) {
+	ctx := context.Background()
+
+	var incr *redis.IntCmd
+	var ttl *redis.DurationCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SetNX(ctx, s.prefix+key, 0, exp)
+		incr = pipe.Incr(ctx, s.prefix+key)
+		ttl = pipe.PTTL(ctx, s.prefix+key)
+		return nil
+	})
+	if err != nil {
+		return Counter{}, err
+	}
+
+	// a counter left without an expiry by a previous version is given one.
+	resetIn := ttl.Val()
+	if resetIn < 0 {
+		resetIn = exp
+		err = s.client.PExpire(ctx, s.prefix+key, exp).Err()
+		if err != nil {
+			return Counter{}, err
+		}
+	}
+
+	return Counter{Count: incr.Val(), ResetIn: resetIn}, nil
+}
+
+func (s *RedisStorage) Delete(key string) error {
+	if len(key) <= 0 {
+		return nil
+	}
+
+	return s.client.Del(context.Background(), s.prefix+key).Err()
+}
+
+// Reset deletes every key with the prefix.
+func (s *RedisStorage) Reset() error {
+	ctx := context.Background()
+
+	iter := s.client.Scan(ctx, 0, s.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		err := s.client.Del(ctx, iter.Val()).Err()
+		if err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+func (s *RedisStorage) Close() error {
+	return s.client.Close()
+}
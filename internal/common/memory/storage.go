@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"strconv"
 	"sync"
 	"time"
 )
@@ -10,6 +11,8 @@ type Storage struct {
 	db         map[string]entry
 	gcInterval time.Duration
 	done       chan struct{}
+	// now is the clock the entries expire by.
+	now func() time.Time
 }
 
 type entry struct {
@@ -22,6 +25,7 @@ func New() *Storage {
 		db:         make(map[string]entry),
 		gcInterval: 10 * time.Second,
 		done:       make(chan struct{}),
+		now:        time.Now,
 	}
 	go store.gc()
 	return store
@@ -34,7 +38,7 @@ func (s *Storage) Get(key string) ([]byte, error) {
 	s.mux.RLock()
 	v, ok := s.db[key]
 	s.mux.RUnlock()
-	if !ok || v.expiry != 0 && v.expiry <= uint32(time.Now().Unix()) {
+	if !ok || v.expiry != 0 && v.expiry <= uint32(s.now().Unix()) {
 		return nil, nil
 	}
 
@@ -48,7 +52,7 @@ func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
 
 	var expire uint32
 	if exp != 0 {
-		expire = uint32(time.Now().Add(exp).Unix())
+		expire = uint32(s.now().Add(exp).Unix())
 	}
 
 	s.mux.Lock()
@@ -57,6 +61,29 @@ func (s *Storage) Set(key string, val []byte, exp time.Duration) error {
 	return nil
 }
 
+func (s *Storage) Increment(key string, exp time.Duration) (Counter, error) {
+	now := s.now()
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	v, ok := s.db[key]
+	if !ok || v.expiry != 0 && v.expiry <= uint32(now.Unix()) {
+		v = entry{expiry: uint32(now.Add(exp).Unix()), data: []byte("0")}
+	}
+
+	count, err := strconv.ParseInt(string(v.data), 10, 64)
+	if err != nil {
+		return Counter{}, err
+	}
+	count++
+
+	v.data = []byte(strconv.FormatInt(count, 10))
+	s.db[key] = v
+
+	return Counter{Count: count, ResetIn: time.Unix(int64(v.expiry), 0).Sub(now)}, nil
+}
+
 func (s *Storage) Delete(key string) error {
 	if len(key) <= 0 {
 		return nil
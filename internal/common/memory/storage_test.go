@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"testing"
+	"time"
+)
+
+// newTestStorage returns a Storage whose clock is moved by hand.
+func newTestStorage(t *testing.T, now *time.Time) *Storage {
+	store := New()
+	store.now = func() time.Time { return *now }
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStorageIncrement(t *testing.T) {
+	tests := []struct {
+		name      string
+		calls     int
+		advance   time.Duration
+		wantCount int64
+		wantReset time.Duration
+	}{
+		{name: "new counter starts at one", calls: 1, wantCount: 1, wantReset: time.Minute},
+		{name: "counter adds one per call", calls: 3, wantCount: 3, wantReset: time.Minute},
+		{name: "reset in shrinks with time", calls: 2, advance: 20 * time.Second, wantCount: 2, wantReset: 40 * time.Second},
+		{name: "expired counter starts over", calls: 3, advance: time.Minute, wantCount: 1, wantReset: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			store := newTestStorage(t, &now)
+
+			var counter Counter
+			for i := 0; i < tt.calls; i++ {
+				// the clock is moved before the last call.
+				if i == tt.calls-1 {
+					now = now.Add(tt.advance)
+				}
+
+				var err error
+				counter, err = store.Increment("counter", time.Minute)
+				if err != nil {
+					t.Fatalf("Increment() error = %v", err)
+				}
+			}
+
+			if counter.Count != tt.wantCount {
+				t.Errorf("Count = %d, want %d", counter.Count, tt.wantCount)
+			}
+			if counter.ResetIn != tt.wantReset {
+				t.Errorf("ResetIn = %v, want %v", counter.ResetIn, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestStorageExpiry(t *testing.T) {
+	tests := []struct {
+		name    string
+		exp     time.Duration
+		advance time.Duration
+		want    string
+	}{
+		{name: "fresh entry is found", exp: time.Minute, advance: 59 * time.Second, want: "value"},
+		{name: "expired entry is not found", exp: time.Minute, advance: time.Minute, want: ""},
+		{name: "zero exp never expires", exp: 0, advance: 24 * time.Hour, want: "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Unix(1700000000, 0)
+			store := newTestStorage(t, &now)
+
+			err := store.Set("key", []byte("value"), tt.exp)
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			now = now.Add(tt.advance)
+			got, err := store.Get("key")
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Get() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+// Package oidc signs users in with an OpenID Connect provider, using the
+// authorization code flow with PKCE. the provider is configured from its
+// discovery document, so that any provider, or a local mock, can be used.
+// the id tokens are verified with github.com/coreos/go-oidc.
+package oidc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	gooidc "github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoIDToken is returned when the token response has no id token.
+	ErrNoIDToken = errors.New("oidc: token response has no id_token")
+	// ErrInvalidIDToken is returned when the id token can not be trusted.
+	ErrInvalidIDToken = errors.New("oidc: id token is invalid")
+)
+
+// Provider is an OpenID Connect provider. its discovery document is fetched
+// on first use, so that the app starts while the provider is unreachable.
+type Provider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	client *http.Client
+	// now is the clock the id tokens are checked against.
+	now func() time.Time
+
+	mu       sync.Mutex
+	provider *gooidc.Provider
+	verifier *gooidc.IDTokenVerifier
+}
+
+// NewProvider creates a new Provider.
+func NewProvider(name, issuer, clientID, clientSecret, redirectURL string) *Provider {
+	return &Provider{
+		Name:         name,
+		Issuer:       issuer,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		now:          time.Now,
+	}
+}
+
+// AuthCodeURL returns the url of the provider to send the user to. the state
+// and the nonce are checked on the way back, the challenge is the one of the
+// verifier given to Exchange, see Challenge.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, challenge string) (string, error) {
+	config, err := p.config(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	), nil
+}
+
+// Exchange exchanges the authorization code for the id token of the user,
+// and returns its claims once they have been verified against the nonce.
+func (p *Provider) Exchange(ctx context.Context, code, verifier, nonce string) (*Claims, error) {
+	config, err := p.config(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = gooidc.ClientContext(ctx, p.client)
+	token, err := config.Exchange(ctx, code, oauth2.SetAuthURLParam("code_verifier", verifier))
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrNoIDToken
+	}
+
+	return p.Verify(ctx, rawIDToken, nonce)
+}
+
+// config returns the oauth2 config of the provider.
+func (p *Provider) config(ctx context.Context) (*oauth2.Config, error) {
+	provider, _, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauth2.Config{
+		ClientID:     p.ClientID,
+		ClientSecret: p.ClientSecret,
+		RedirectURL:  p.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       []string{"openid", "email", "profile"},
+	}, nil
+}
+
+// discover returns the provider and the verifier of its id tokens, the discovery
+// document is fetched once, the keys when they are needed.
+func (p *Provider) discover(ctx context.Context) (*gooidc.Provider, *gooidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.provider != nil {
+		return p.provider, p.verifier, nil
+	}
+
+	// the document must belong to the issuer it has been fetched from,
+	// go-oidc checks it.
+	provider, err := gooidc.NewProvider(gooidc.ClientContext(ctx, p.client), p.Issuer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	discovery := struct {
+		JWKSURI string `json:"jwks_uri"`
+	}{}
+	err = provider.Claims(&discovery)
+	if err != nil {
+		return nil, nil, err
+	}
+	if provider.Endpoint().AuthURL == "" || provider.Endpoint().TokenURL == "" || discovery.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("oidc: discovery document of %q is incomplete", p.Issuer)
+	}
+
+	keys := newKeySet(discovery.JWKSURI, p.client, p.now)
+	p.provider = provider
+	p.verifier = gooidc.NewVerifier(p.Issuer, keys, &gooidc.Config{
+		ClientID:             p.ClientID,
+		SupportedSigningAlgs: []string{gooidc.RS256, gooidc.ES256},
+		Now:                  p.now,
+	})
+	return p.provider, p.verifier, nil
+}
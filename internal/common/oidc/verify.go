@@ -0,0 +1,225 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/square/go-jose.v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// clockSkew is how far the clocks of the app and the provider may differ.
+	clockSkew = time.Minute
+	// refetchInterval is how often the keys of a provider are fetched at most,
+	// so that tokens signed with unknown keys can not make the app call the
+	// provider on every request.
+	refetchInterval = time.Minute
+	// minRSABits is the size of the smallest rsa key that is trusted.
+	minRSABits = 2048
+)
+
+// Claims are the claims of an id token that are used.
+type Claims struct {
+	Subject       string  `json:"sub"`
+	AuthorizedBy  string  `json:"azp"`
+	Email         string  `json:"email"`
+	EmailVerified boolean `json:"email_verified"`
+	Name          string  `json:"name"`
+}
+
+// Verify checks the signature of the id token with the keys of the provider,
+// and that it has been issued by the provider to this client for the nonce.
+func (p *Provider) Verify(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	_, verifier, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// go-oidc checks the signature, the issuer, the audience and the expiry.
+	token, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	claims := &Claims{}
+	err = token.Claims(claims)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	switch {
+	case token.Expiry.IsZero():
+		return nil, fmt.Errorf("%w: no expiry", ErrInvalidIDToken)
+	case token.IssuedAt.IsZero():
+		return nil, fmt.Errorf("%w: no issue time", ErrInvalidIDToken)
+	case token.IssuedAt.After(p.now().Add(clockSkew)):
+		return nil, fmt.Errorf("%w: issued in the future", ErrInvalidIDToken)
+	case len(token.Audience) > 1 && claims.AuthorizedBy != p.ClientID:
+		return nil, fmt.Errorf("%w: not authorized by this client", ErrInvalidIDToken)
+	case token.Nonce != nonce:
+		return nil, fmt.Errorf("%w: unexpected nonce", ErrInvalidIDToken)
+	case token.Subject == "":
+		return nil, fmt.Errorf("%w: no subject", ErrInvalidIDToken)
+	}
+
+	return claims, nil
+}
+
+// keySet is the json web key set of a provider, fetched again when a token
+// is signed with a key it does not have, after the provider rotated its keys,
+// but not more than once every refetchInterval.
+type keySet struct {
+	uri    string
+	client *http.Client
+	now    func() time.Time
+
+	mu        sync.Mutex
+	keys      []jose.JSONWebKey
+	fetchedAt time.Time
+}
+
+func newKeySet(uri string, client *http.Client, now func() time.Time) *keySet {
+	return &keySet{uri: uri, client: client, now: now}
+}
+
+// VerifySignature verifies the signature of the jwt and returns its payload,
+// it implements the KeySet of go-oidc.
+func (k *keySet) VerifySignature(ctx context.Context, jwt string) ([]byte, error) {
+	jws, err := jose.ParseSigned(jwt)
+	if err != nil {
+		return nil, err
+	}
+	if len(jws.Signatures) != 1 {
+		return nil, errors.New("jwt must have exactly one signature")
+	}
+
+	keys, err := k.find(ctx, jws.Signatures[0].Header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range keys {
+		payload, err := jws.Verify(&keys[i])
+		if err == nil {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("signature does not match the keys of the provider")
+}
+
+// find returns the keys with the kid, or every key when the jwt has no kid.
+func (k *keySet) find(ctx context.Context, kid string) ([]jose.JSONWebKey, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	keys := withKeyID(k.keys, kid)
+	if len(keys) > 0 {
+		return keys, nil
+	}
+
+	now := k.now()
+	if !k.fetchedAt.IsZero() && now.Sub(k.fetchedAt) < refetchInterval {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	k.fetchedAt = now
+
+	fetched, err := k.fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	k.keys = fetched
+
+	keys = withKeyID(k.keys, kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return keys, nil
+}
+
+func (k *keySet) fetch(ctx context.Context) ([]jose.JSONWebKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.uri, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc: get %s: %s", k.uri, resp.Status)
+	}
+
+	// the keys are decoded one by one, so that a key of an unknown type
+	// does not make the whole set unusable.
+	set := struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []jose.JSONWebKey{}
+	for _, raw := range set.Keys {
+		key := jose.JSONWebKey{}
+		if json.Unmarshal(raw, &key) != nil || !trusted(key) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// trusted reports whether the key may verify the signature of id tokens,
+// weak rsa keys are left out.
+func trusted(key jose.JSONWebKey) bool {
+	if key.Use != "" && key.Use != "sig" {
+		return false
+	}
+	if !key.Valid() || !key.IsPublic() {
+		return false
+	}
+
+	switch public := key.Key.(type) {
+	case *rsa.PublicKey:
+		return public.N.BitLen() >= minRSABits && public.E >= 3 && public.E%2 == 1
+	case *ecdsa.PublicKey:
+		return true
+	default:
+		return false
+	}
+}
+
+func withKeyID(keys []jose.JSONWebKey, kid string) []jose.JSONWebKey {
+	found := []jose.JSONWebKey{}
+	for _, key := range keys {
+		if kid == "" || key.KeyID == kid {
+			found = append(found, key)
+		}
+	}
+	return found
+}
+
+// boolean is the email_verified claim, which some providers send as a string.
+type boolean bool
+
+func (v *boolean) UnmarshalJSON(b []byte) error {
+	switch strings.Trim(string(b), `"`) {
+	case "true":
+		*v = true
+	default:
+		*v = false
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"gopkg.in/square/go-jose.v2"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mockProvider is an OpenID Connect provider that serves its discovery
+// document and its keys, and signs id tokens.
+type mockProvider struct {
+	*httptest.Server
+	key        *rsa.PrivateKey
+	keys       []jose.JSONWebKey
+	keyFetches int32
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &mockProvider{key: key}
+	m.keys = []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "key", Algorithm: "RS256", Use: "sig"}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 m.URL,
+			"authorization_endpoint": m.URL + "/authorize",
+			"token_endpoint":         m.URL + "/token",
+			"jwks_uri":               m.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&m.keyFetches, 1)
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: m.keys})
+	})
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+
+	return m
+}
+
+// sign signs the claims with the key under the kid.
+func (m *mockProvider) sign(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithHeader("kid", kid),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := signed.CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// claims returns valid claims, with the changes applied, a nil value removes the claim.
+func (m *mockProvider) claims(now time.Time, changes map[string]interface{}) map[string]interface{} {
+	claims := map[string]interface{}{
+		"iss":            m.URL,
+		"sub":            "subject",
+		"aud":            "client",
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          "nonce",
+		"email":          "user@example.com",
+		"email_verified": "true",
+	}
+	for name, value := range changes {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestVerify(t *testing.T) {
+	m := newMockProvider(t)
+	now := time.Now()
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     *rsa.PrivateKey
+		changes map[string]interface{}
+		wantErr bool
+	}{
+		{name: "valid"},
+		{name: "signed by another key", key: other, wantErr: true},
+		{name: "other issuer", changes: map[string]interface{}{"iss": "https://issuer.example.com"}, wantErr: true},
+		{name: "other audience", changes: map[string]interface{}{"aud": "other"}, wantErr: true},
+		{name: "many audiences without azp", changes: map[string]interface{}{"aud": []string{"client", "other"}}, wantErr: true},
+		{name: "many audiences with azp", changes: map[string]interface{}{"aud": []string{"client", "other"}, "azp": "client"}},
+		{name: "expired", changes: map[string]interface{}{"exp": now.Add(-time.Minute).Unix()}, wantErr: true},
+		{name: "no expiry", changes: map[string]interface{}{"exp": nil}, wantErr: true},
+		{name: "no issue time", changes: map[string]interface{}{"iat": nil}, wantErr: true},
+		{name: "issued in the future", changes: map[string]interface{}{"iat": now.Add(time.Hour).Unix()}, wantErr: true},
+		{name: "other nonce", changes: map[string]interface{}{"nonce": "other"}, wantErr: true},
+		{name: "no subject", changes: map[string]interface{}{"sub": nil}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewProvider("mock", m.URL, "client", "secret", "http://localhost/callback")
+			p.now = func() time.Time { return now }
+
+			key := tt.key
+			if key == nil {
+				key = m.key
+			}
+			token := m.sign(t, key, "key", m.claims(now, tt.changes))
+
+			claims, err := p.Verify(context.Background(), token, "nonce")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Errorf("Verify() error = %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "subject" || claims.Email != "user@example.com" || !claims.EmailVerified {
+				t.Errorf("Verify() claims = %+v", claims)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsWeakKeys(t *testing.T) {
+	m := newMockProvider(t)
+	now := time.Now()
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exponentOne := m.key.PublicKey
+	exponentOne.E = 1
+	m.keys = []jose.JSONWebKey{
+		{Key: &weak.PublicKey, KeyID: "weak", Algorithm: "RS256", Use: "sig"},
+		{Key: &exponentOne, KeyID: "exponent", Algorithm: "RS256", Use: "sig"},
+	}
+
+	p := NewProvider("mock", m.URL, "client", "secret", "http://localhost/callback")
+	p.now = func() time.Time { return now }
+
+	_, err = p.Verify(context.Background(), m.sign(t, weak, "weak", m.claims(now, nil)), "nonce")
+	if !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Verify() with a 1024 bits key error = %v, want ErrInvalidIDToken", err)
+	}
+	_, err = p.Verify(context.Background(), m.sign(t, m.key, "exponent", m.claims(now, nil)), "nonce")
+	if !errors.Is(err, ErrInvalidIDToken) {
+		t.Errorf("Verify() with an exponent of 1 error = %v, want ErrInvalidIDToken", err)
+	}
+}
+
+func TestVerifyThrottlesKeyFetches(t *testing.T) {
+	m := newMockProvider(t)
+	now := time.Now()
+	p := NewProvider("mock", m.URL, "client", "secret", "http://localhost/callback")
+	p.now = func() time.Time { return now }
+
+	_, err := p.Verify(context.Background(), m.sign(t, m.key, "key", m.claims(now, nil)), "nonce")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// tokens with unknown keys do not fetch the keys again within refetchInterval.
+	for i := 0; i < 5; i++ {
+		_, err = p.Verify(context.Background(), m.sign(t, m.key, "unknown", m.claims(now, nil)), "nonce")
+		if !errors.Is(err, ErrInvalidIDToken) {
+			t.Fatalf("Verify() with an unknown key error = %v, want ErrInvalidIDToken", err)
+		}
+	}
+	if fetches := atomic.LoadInt32(&m.keyFetches); fetches != 1 {
+		t.Errorf("keys fetched %d times, want 1", fetches)
+	}
+
+	// a rotated key is found once refetchInterval has passed.
+	m.keys = append(m.keys, jose.JSONWebKey{Key: &m.key.PublicKey, KeyID: "rotated", Algorithm: "RS256", Use: "sig"})
+	now = now.Add(refetchInterval)
+	_, err = p.Verify(context.Background(), m.sign(t, m.key, "rotated", m.claims(now, nil)), "nonce")
+	if err != nil {
+		t.Fatalf("Verify() with a rotated key error = %v", err)
+	}
+	if fetches := atomic.LoadInt32(&m.keyFetches); fetches != 2 {
+		t.Errorf("keys fetched %d times, want 2", fetches)
+	}
+}
@@ -0,0 +1,37 @@
+package pagination
+
+import "strconv"
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 100
+)
+
+// Pagination holds the page and limit requested by the client.
+type Pagination struct {
+	Page  int
+	Limit int
+}
+
+// New parses page and limit from query string values,
+// falling back to the first page and the default limit.
+func New(page, limit string) Pagination {
+	p := Pagination{Page: 1, Limit: DefaultLimit}
+
+	if n, err := strconv.Atoi(page); err == nil && n > 0 {
+		p.Page = n
+	}
+	if n, err := strconv.Atoi(limit); err == nil && n > 0 {
+		p.Limit = n
+	}
+	if p.Limit > MaxLimit {
+		p.Limit = MaxLimit
+	}
+
+	return p
+}
+
+// Offset returns the number of rows to skip.
+func (p Pagination) Offset() int {
+	return (p.Page - 1) * p.Limit
+}
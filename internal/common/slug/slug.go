@@ -0,0 +1,65 @@
+package slug
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mozillazg/go-unidecode"
+	"strings"
+)
+
+// MaxLength leaves room for a collision suffix in a VARCHAR(100) column.
+const MaxLength = 90
+
+// maxAttempts bounds the search for a free slug.
+const maxAttempts = 1000
+
+var ErrNoFreeSlug = errors.New("no free slug available")
+
+// replacer spells out the symbols that would otherwise be dropped.
+var replacer = strings.NewReplacer("&", " and ", "@", " at ")
+
+// Make turns a title into a lowercase, dash separated ascii slug.
+// accents are removed and the other scripts are transliterated,
+// so "Crème Brûlée" becomes "creme-brulee", "Привет мир" "privet-mir"
+// and "北京" "bei-jing". the letters that have no transliteration are dropped.
+func Make(title string) string {
+	title = unidecode.Unidecode(replacer.Replace(title))
+
+	var slug strings.Builder
+	length := 0
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if length >= MaxLength {
+			break
+		}
+		if 'a' <= r && r <= 'z' || '0' <= r && r <= '9' {
+			slug.WriteRune(r)
+			length++
+			dash = false
+			continue
+		}
+		if !dash && slug.Len() > 0 {
+			slug.WriteRune('-')
+			length++
+			dash = true
+		}
+	}
+	return strings.TrimSuffix(slug.String(), "-")
+}
+
+// Unique returns the base slug, or the first of base-2, base-3, ...
+// for which exists reports false.
+func Unique(base string, exists func(candidate string) (bool, error)) (string, error) {
+	candidate := base
+	for i := 2; i <= maxAttempts; i++ {
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", ErrNoFreeSlug
+}
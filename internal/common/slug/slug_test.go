@@ -0,0 +1,55 @@
+package slug
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{title: "Hello, World!", want: "hello-world"},
+		{title: "  Go 1.17 -- released  ", want: "go-1-17-released"},
+		{title: "Crème Brûlée", want: "creme-brulee"},
+		{title: "Straße & Œuvre", want: "strasse-and-oeuvre"},
+		{title: "Łódź @ night", want: "lodz-at-night"},
+		{title: "Привет мир", want: "privet-mir"},
+		{title: "Ελληνικά", want: "ellenika"},
+		{title: "北京", want: "bei-jing"},
+		{title: "こんにちは", want: "konnichiha"},
+		{title: "안녕하세요", want: "annyeonghaseyo"},
+		{title: "مرحبا", want: "mrhb"},
+		{title: "🙂🙂", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			if got := Make(tt.title); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeMaxLength(t *testing.T) {
+	got := Make(strings.Repeat("Ж", 100))
+	if len(got) > MaxLength {
+		t.Errorf("len(Make()) = %d, want at most %d", len(got), MaxLength)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	taken := map[string]bool{"go": true, "go-2": true}
+	got, err := Unique("go", func(candidate string) (bool, error) { return taken[candidate], nil })
+	if err != nil || got != "go-3" {
+		t.Errorf("Unique() = %q, %v, want go-3", got, err)
+	}
+
+	_, err = Unique("go", func(string) (bool, error) { return true, nil })
+	if !errors.Is(err, ErrNoFreeSlug) {
+		t.Errorf("Unique() error = %v, want ErrNoFreeSlug", err)
+	}
+}
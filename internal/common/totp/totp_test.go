@@ -0,0 +1,134 @@
+package totp
+
+import (
+	"encoding/base32"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed of the test vectors of RFC 6238, base32 encoded.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	// the codes are the last 6 digits of the 8 digit codes of RFC 6238 appendix B.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code(%d) error = %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("Code(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	codeAt := func(step int64) string {
+		code, err := Code(rfcSecret, step)
+		if err != nil {
+			t.Fatalf("Code() error = %v", err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{name: "current step", code: codeAt(current), wantStep: current, wantOK: true},
+		{name: "previous step within skew", code: codeAt(current - Skew), wantStep: current - Skew, wantOK: true},
+		{name: "next step within skew", code: codeAt(current + Skew), wantStep: current + Skew, wantOK: true},
+		{name: "step before the window", code: codeAt(current - Skew - 1), wantOK: false},
+		{name: "step after the window", code: codeAt(current + Skew + 1), wantOK: false},
+		{name: "too short", code: "12345", wantOK: false},
+		{name: "too long", code: "1234567", wantOK: false},
+		{name: "empty", code: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := Validate(rfcSecret, tt.code, now)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("Validate() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("Validate() step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestValidateMalformedSecret(t *testing.T) {
+	_, _, err := Validate("not base32!", "123456", time.Unix(1234567890, 0))
+	if err == nil {
+		t.Error("Validate() error = nil, want an error for a malformed secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	second, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+
+	key, err := encoding.DecodeString(first)
+	if err != nil {
+		t.Fatalf("secret is not base32: %v", err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("secret is %d bytes, want %d", len(key), secretSize)
+	}
+	if first == second {
+		t.Error("two secrets are the same")
+	}
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Blog", "jane@example.com", rfcSecret))
+	if err != nil {
+		t.Fatalf("URI() is not a url: %v", err)
+	}
+
+	if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/Blog:jane@example.com" {
+		t.Errorf("URI() = %s, want otpauth://totp/Blog:jane@example.com", uri)
+	}
+
+	params := uri.Query()
+	want := map[string]string{
+		"secret":    rfcSecret,
+		"issuer":    "Blog",
+		"algorithm": "SHA1",
+		"digits":    "6",
+		"period":    "30",
+	}
+	for key, value := range want {
+		if got := params.Get(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
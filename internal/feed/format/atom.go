@@ -0,0 +1,78 @@
+package format
+
+import (
+	"encoding/xml"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	"time"
+)
+
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomContent struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	ID        string      `xml:"id"`
+	Title     string      `xml:"title"`
+	Links     []atomLink  `xml:"link"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Author    atomAuthor  `xml:"author"`
+	Summary   string      `xml:"summary,omitempty"`
+	Content   atomContent `xml:"content"`
+}
+
+// Atom encodes the feed as Atom 1.0.
+func Atom(feed storage.Feed) ([]byte, error) {
+	updated := feed.Updated
+	if updated.IsZero() {
+		updated = time.Unix(0, 0)
+	}
+
+	document := atomFeed{
+		ID:       feed.FeedLink,
+		Title:    feed.Title,
+		Subtitle: feed.Description,
+		Updated:  updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Href: feed.Link, Rel: "alternate", Type: "text/html"},
+			{Href: feed.FeedLink, Rel: "self", Type: "application/atom+xml"},
+		},
+		Entries: []atomEntry{},
+	}
+
+	for _, item := range feed.Items {
+		document.Entries = append(document.Entries, atomEntry{
+			ID:        "urn:uuid:" + item.ID,
+			Title:     item.Title,
+			Links:     []atomLink{{Href: item.Link, Rel: "alternate", Type: "text/html"}},
+			Published: item.PublishedAt.UTC().Format(time.RFC3339),
+			Updated:   item.LastUpdated.UTC().Format(time.RFC3339),
+			Author:    atomAuthor{Name: item.AuthorName},
+			Summary:   item.Excerpt,
+			Content:   atomContent{Type: "html", Value: item.ContentHTML},
+		})
+	}
+
+	return encodeXML(document)
+}
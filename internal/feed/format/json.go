@@ -0,0 +1,58 @@
+package format
+
+import (
+	"encoding/json"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	"time"
+)
+
+type jsonFeed struct {
+	Version     string     `json:"version"`
+	Title       string     `json:"title"`
+	HomePageURL string     `json:"home_page_url"`
+	FeedURL     string     `json:"feed_url"`
+	Description string     `json:"description,omitempty"`
+	Items       []jsonItem `json:"items"`
+}
+
+type jsonAuthor struct {
+	Name string `json:"name"`
+}
+
+type jsonItem struct {
+	ID            string       `json:"id"`
+	URL           string       `json:"url"`
+	Title         string       `json:"title"`
+	Summary       string       `json:"summary,omitempty"`
+	ContentHTML   string       `json:"content_html"`
+	DatePublished string       `json:"date_published"`
+	DateModified  string       `json:"date_modified"`
+	Authors       []jsonAuthor `json:"authors"`
+}
+
+// JSON encodes the feed as JSON Feed 1.1.
+func JSON(feed storage.Feed) ([]byte, error) {
+	document := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       feed.Title,
+		HomePageURL: feed.Link,
+		FeedURL:     feed.FeedLink,
+		Description: feed.Description,
+		Items:       []jsonItem{},
+	}
+
+	for _, item := range feed.Items {
+		document.Items = append(document.Items, jsonItem{
+			ID:            item.ID,
+			URL:           item.Link,
+			Title:         item.Title,
+			Summary:       item.Excerpt,
+			ContentHTML:   item.ContentHTML,
+			DatePublished: item.PublishedAt.UTC().Format(time.RFC3339),
+			DateModified:  item.LastUpdated.UTC().Format(time.RFC3339),
+			Authors:       []jsonAuthor{{Name: item.AuthorName}},
+		})
+	}
+
+	return json.MarshalIndent(document, "", "  ")
+}
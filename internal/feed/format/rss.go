@@ -0,0 +1,95 @@
+package format
+
+import (
+	"encoding/xml"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	"net/http"
+)
+
+type rss struct {
+	XMLName   xml.Name   `xml:"rss"`
+	Version   string     `xml:"version,attr"`
+	ContentNS string     `xml:"xmlns:content,attr"`
+	AtomNS    string     `xml:"xmlns:atom,attr"`
+	DCNS      string     `xml:"xmlns:dc,attr"`
+	Channel   rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Creator     string  `xml:"dc:creator"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description"`
+	Content     cdata   `xml:"content:encoded"`
+}
+
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// RSS encodes the feed as RSS 2.0.
+func RSS(feed storage.Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+		AtomLink:    rssLink{Href: feed.FeedLink, Rel: "self", Type: "application/rss+xml"},
+		Items:       []rssItem{},
+	}
+	if !feed.Updated.IsZero() {
+		channel.LastBuildDate = feed.Updated.UTC().Format(http.TimeFormat)
+	}
+
+	for _, item := range feed.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.ID, IsPermaLink: false},
+			Creator:     item.AuthorName,
+			PubDate:     item.PublishedAt.UTC().Format(http.TimeFormat),
+			Description: item.Excerpt,
+			Content:     cdata{Value: item.ContentHTML},
+		})
+	}
+
+	document := rss{
+		Version:   "2.0",
+		ContentNS: "http://purl.org/rss/1.0/modules/content/",
+		AtomNS:    "http://www.w3.org/2005/Atom",
+		DCNS:      "http://purl.org/dc/elements/1.1/",
+		Channel:   channel,
+	}
+
+	return encodeXML(document)
+}
+
+// encodeXML encodes the document with an xml declaration.
+func encodeXML(document interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,125 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/feed/query"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type FeedQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewFeedQueryPostgresql(DB *pgxpool.Pool) *FeedQueryPostgresql {
+	return &FeedQueryPostgresql{DB: DB}
+}
+
+// FindItems returns the latest published posts matching the filter.
+func (f FeedQueryPostgresql) FindItems(ctx context.Context, filter storage.Filter, limit int) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := f.DB.Query(ctx, `SELECT posts.id, posts.title, posts.slug, posts.excerpt, posts.content,
+			posts.published_at, posts.updated_at, users.name, users.nickname
+			FROM posts
+			JOIN users ON users.id = posts.author_id
+			WHERE posts.status = 'published'
+			AND ($1 = '' OR users.nickname = $1)
+			AND ($2 = '' OR posts.id IN (
+				SELECT posts_tags.post_id FROM posts_tags
+				JOIN tags ON tags.id = posts_tags.tag_id
+				WHERE tags.slug = $2))
+			ORDER BY posts.published_at DESC
+			LIMIT $3`, filter.Nickname, filter.TagSlug, limit)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		items := []storage.Item{}
+		for rows.Next() {
+			item := storage.Item{}
+			err := rows.Scan(
+				&item.ID,
+				&item.Title,
+				&item.Slug,
+				&item.Excerpt,
+				&item.Content,
+				&item.PublishedAt,
+				&item.LastUpdated,
+				&item.AuthorName,
+				&item.Nickname,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			items = append(items, item)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: items}
+	}()
+
+	return result
+}
+
+func (f FeedQueryPostgresql) AuthorExists(ctx context.Context, nickname string) <-chan query.Result {
+	return f.exists(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE nickname = $1)`, nickname)
+}
+
+func (f FeedQueryPostgresql) TagExists(ctx context.Context, slug string) <-chan query.Result {
+	return f.exists(ctx, `SELECT EXISTS (SELECT 1 FROM tags WHERE slug = $1)`, slug)
+}
+
+func (f FeedQueryPostgresql) LastChanged(ctx context.Context, filter storage.Filter) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		// the rows of feed_changes are written by the triggers of the posts, their tags and their authors.
+		changedAt := time.Time{}
+		err := f.DB.QueryRow(ctx, `SELECT changed_at FROM feed_changes WHERE feed = CASE
+			WHEN $1 <> '' THEN (SELECT 'author:' || id FROM users WHERE nickname = $1)
+			WHEN $2 <> '' THEN (SELECT 'tag:' || id FROM tags WHERE slug = $2)
+			ELSE '' END`, filter.Nickname, filter.TagSlug).Scan(&changedAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: changedAt}
+	}()
+
+	return result
+}
+
+func (f FeedQueryPostgresql) exists(ctx context.Context, sql string, arg string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		exists := false
+		err := f.DB.QueryRow(ctx, sql, arg).Scan(&exists)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: exists}
+	}()
+
+	return result
+}
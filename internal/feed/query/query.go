@@ -0,0 +1,21 @@
+package query
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+)
+
+type FeedQuery interface {
+	FindItems(ctx context.Context, filter storage.Filter, limit int) <-chan Result
+	// AuthorExists and TagExists tell whether the feed of the filter belongs to anyone.
+	AuthorExists(ctx context.Context, nickname string) <-chan Result
+	TagExists(ctx context.Context, slug string) <-chan Result
+	// LastChanged returns when the feed of the filter last changed, including
+	// when a post left it, or the zero time when that is not known.
+	LastChanged(ctx context.Context, filter storage.Filter) <-chan Result
+}
+
+type Result struct {
+	Result interface{}
+	Error  error
+}
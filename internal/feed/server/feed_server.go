@@ -0,0 +1,116 @@
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"github.com/SemmiDev/blog/internal/feed/format"
+	queryPostgresql "github.com/SemmiDev/blog/internal/feed/query/postgresql"
+	"github.com/SemmiDev/blog/internal/feed/service"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	rssContentType  = "application/rss+xml; charset=utf-8"
+	atomContentType = "application/atom+xml; charset=utf-8"
+	jsonContentType = "application/feed+json; charset=utf-8"
+)
+
+// encoder encodes a feed to one of the feed formats.
+type encoder func(feed storage.Feed) ([]byte, error)
+
+// FeedServer is the struct that contains FeedService.
+// it will be used to interact with the service.
+type FeedServer struct {
+	FeedService service.FeedService
+}
+
+// NewFeedServer creates a new FeedServer.
+func NewFeedServer(db *pgxpool.Pool) (*FeedServer, error) {
+	feedServiceImpl := &service.FeedServiceImpl{
+		FeedQuery: queryPostgresql.NewFeedQueryPostgresql(db),
+	}
+
+	return &FeedServer{FeedService: feedServiceImpl}, nil
+}
+
+// Mount mounts the feed server to the fiber app.
+// the site-wide feeds are at the root, the author and tag feeds
+// are next to the author and tag they belong to.
+func (s *FeedServer) Mount(r fiber.Router) {
+	for _, prefix := range []string{"", "/authors/:nickname", "/tags/:slug"} {
+		r.Get(prefix+"/feed.xml", s.FeedHandler(format.RSS, rssContentType))
+		r.Get(prefix+"/atom.xml", s.FeedHandler(format.Atom, atomContentType))
+		r.Get(prefix+"/feed.json", s.FeedHandler(format.JSON, jsonContentType))
+	}
+}
+
+// FeedHandler returns a handler that serves the feed with the encoder.
+// it answers 304 Not Modified when the client already has the feed,
+// based on the ETag and Last-Modified headers. Last-Modified is checked
+// before the feed is rendered, so that a fresh copy costs no rendering.
+func (s *FeedServer) FeedHandler(encode encoder, contentType string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		filter := storage.Filter{
+			Nickname: c.Params("nickname"),
+			TagSlug:  c.Params("slug"),
+		}
+
+		feed, err := s.FeedService.FindFeed(c.Context(), filter, c.Path())
+		if err != nil {
+			return helper.Error(c, err)
+		}
+
+		if !feed.Updated.IsZero() {
+			c.Set(fiber.HeaderLastModified, feed.Updated.UTC().Format(http.TimeFormat))
+		}
+		if c.Get(fiber.HeaderIfNoneMatch) == "" && notModifiedSince(c, feed.Updated) {
+			return c.SendStatus(http.StatusNotModified)
+		}
+
+		feed, err = s.FeedService.RenderFeed(feed)
+		if err != nil {
+			return helper.Error(c, err)
+		}
+
+		body, err := encode(feed)
+		if err != nil {
+			return helper.Error(c, err)
+		}
+
+		etag := fmt.Sprintf(`"%x"`, sha1.Sum(body))
+		c.Set(fiber.HeaderETag, etag)
+		if matchesETag(c, etag) {
+			return c.SendStatus(http.StatusNotModified)
+		}
+
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Status(http.StatusOK).Send(body)
+	}
+}
+
+// matchesETag reports whether If-None-Match lists the etag,
+// weakly compared, or is "*". If-None-Match takes precedence over If-Modified-Since.
+func matchesETag(c *fiber.Ctx, etag string) bool {
+	for _, match := range strings.Split(c.Get(fiber.HeaderIfNoneMatch), ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+		if match == etag || match == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// notModifiedSince reports whether the feed has not changed since If-Modified-Since.
+func notModifiedSince(c *fiber.Ctx, lastModified time.Time) bool {
+	since, err := http.ParseTime(c.Get(fiber.HeaderIfModifiedSince))
+	if err != nil || lastModified.IsZero() {
+		return false
+	}
+	return !lastModified.Truncate(time.Second).After(since)
+}
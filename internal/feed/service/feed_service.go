@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	"github.com/SemmiDev/blog/internal/feed/query"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"strings"
+	"time"
+)
+
+// feedSize is the number of posts in a feed.
+const feedSize = 20
+
+// FeedServiceImpl is a struct that implements FeedService interface.
+type FeedServiceImpl struct {
+	FeedQuery query.FeedQuery
+}
+
+// FindFeed returns the latest published posts matching the filter as a feed,
+// the content of the posts is rendered by RenderFeed.
+// feedPath is the path the feed is served from, it is used as the feed's own link.
+func (s *FeedServiceImpl) FindFeed(ctx context.Context, filter storage.Filter, feedPath string) (storage.Feed, error) {
+	if filter.Nickname != "" {
+		err := s.mustExist(s.FeedQuery.AuthorExists(ctx, filter.Nickname), "nickname")
+		if err != nil {
+			return storage.Feed{}, err
+		}
+	}
+	if filter.TagSlug != "" {
+		err := s.mustExist(s.FeedQuery.TagExists(ctx, filter.TagSlug), "tag")
+		if err != nil {
+			return storage.Feed{}, err
+		}
+	}
+
+	result := <-s.FeedQuery.FindItems(ctx, filter, feedSize)
+	if result.Error != nil {
+		return storage.Feed{}, result.Error
+	}
+
+	items, ok := result.Result.([]storage.Item)
+	if !ok {
+		return storage.Feed{}, errors.New("helper type assertion")
+	}
+
+	changed := <-s.FeedQuery.LastChanged(ctx, filter)
+	if changed.Error != nil {
+		return storage.Feed{}, changed.Error
+	}
+
+	changedAt, ok := changed.Result.(time.Time)
+	if !ok {
+		return storage.Feed{}, errors.New("helper type assertion")
+	}
+
+	siteURL := strings.TrimSuffix(config.Env.SiteURL, "/")
+	feed := storage.Feed{
+		Title:       config.Env.SiteTitle,
+		Description: fmt.Sprintf("Latest posts of %s", config.Env.SiteTitle),
+		Link:        siteURL,
+		FeedLink:    siteURL + feedPath,
+		// the feed also changes when a post leaves it,
+		// which the posts still in it can not tell.
+		Updated: changedAt,
+	}
+
+	if filter.Nickname != "" {
+		feed.Title = fmt.Sprintf("%s - posts by %s", config.Env.SiteTitle, filter.Nickname)
+		feed.Description = fmt.Sprintf("Latest posts of %s on %s", filter.Nickname, config.Env.SiteTitle)
+	}
+	if filter.TagSlug != "" {
+		feed.Title = fmt.Sprintf("%s - posts tagged %s", config.Env.SiteTitle, filter.TagSlug)
+		feed.Description = fmt.Sprintf("Latest posts tagged %s on %s", filter.TagSlug, config.Env.SiteTitle)
+	}
+
+	for i := range items {
+		items[i].Link = siteURL + "/posts/" + items[i].Slug
+
+		// the feed changes when a post is published or updated.
+		if items[i].LastUpdated.After(feed.Updated) {
+			feed.Updated = items[i].LastUpdated
+		}
+		if items[i].PublishedAt.After(feed.Updated) {
+			feed.Updated = items[i].PublishedAt
+		}
+	}
+	feed.Items = items
+
+	return feed, nil
+}
+
+// RenderFeed renders the markdown content of the posts of the feed to html.
+func (s *FeedServiceImpl) RenderFeed(feed storage.Feed) (storage.Feed, error) {
+	for i := range feed.Items {
+		document, err := markdown.Render(feed.Items[i].Content)
+		if err != nil {
+			return storage.Feed{}, err
+		}
+		feed.Items[i].ContentHTML = document.HTML
+	}
+	return feed, nil
+}
+
+// mustExist returns a not found error for the field when the query reports false.
+func (s *FeedServiceImpl) mustExist(queryResult <-chan query.Result, field string) error {
+	result := <-queryResult
+	if result.Error != nil {
+		return result.Error
+	}
+
+	exists, ok := result.Result.(bool)
+	if !ok {
+		return errors.New("helper type assertion")
+	}
+	if !exists {
+		return NewErr(ErrNotFoundCode, field)
+	}
+	return nil
+}
@@ -0,0 +1,12 @@
+package service
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/feed/storage"
+)
+
+// FeedService is a service for building the feeds of published posts.
+type FeedService interface {
+	FindFeed(ctx context.Context, filter storage.Filter, feedPath string) (storage.Feed, error)
+	RenderFeed(feed storage.Feed) (storage.Feed, error)
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"time"
+)
+
+// Filter narrows a feed down to an author or a tag.
+// empty fields are not filtered on.
+type Filter struct {
+	Nickname string
+	TagSlug  string
+}
+
+// Item is a published post as an entry of a feed.
+type Item struct {
+	ID          string
+	Title       string
+	Slug        string
+	Link        string
+	Excerpt     string
+	Content     string
+	ContentHTML string
+	AuthorName  string
+	Nickname    string
+	PublishedAt time.Time
+	LastUpdated time.Time
+}
+
+// Feed is the format independent content of a feed.
+type Feed struct {
+	ID          string
+	Title       string
+	Description string
+	Link        string
+	FeedLink    string
+	Updated     time.Time
+	Items       []Item
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// Favorite represents a favorites table in the database.
+type Favorite struct {
+	ID          string
+	UserID      string
+	PostID      string
+	CreatedDate time.Time
+}
+
+// CreateFavorite creates a new favorite of the user and returns it.
+func CreateFavorite(userID, postID string) *Favorite {
+	return &Favorite{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		PostID:      postID,
+		CreatedDate: time.Now(),
+	}
+}
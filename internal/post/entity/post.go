@@ -0,0 +1,198 @@
+package entity
+
+import (
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/slug"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+// excerptLength is the maximum length of the excerpt column.
+const excerptLength = 100
+
+// DefaultLanguage is the text search configuration used to stem
+// a post when its author did not choose a language.
+const DefaultLanguage = "english"
+
+// languages are the PostgreSQL text search configurations a post can be written in.
+var languages = map[string]bool{
+	"simple":     true,
+	"arabic":     true,
+	"danish":     true,
+	"dutch":      true,
+	"english":    true,
+	"finnish":    true,
+	"french":     true,
+	"german":     true,
+	"hungarian":  true,
+	"indonesian": true,
+	"italian":    true,
+	"norwegian":  true,
+	"portuguese": true,
+	"romanian":   true,
+	"russian":    true,
+	"spanish":    true,
+	"swedish":    true,
+	"turkish":    true,
+}
+
+// IsLanguage reports whether lang is a supported text search configuration.
+func IsLanguage(lang string) bool {
+	return languages[lang]
+}
+
+// Status is the publication state of a post.
+type Status string
+
+const (
+	StatusDraft     Status = "draft"
+	StatusScheduled Status = "scheduled"
+	StatusPublished Status = "published"
+	StatusArchived  Status = "archived"
+)
+
+var (
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrScheduleInPast    = errors.New("schedule time must be in the future")
+)
+
+// Post represents a post table in the database.
+type Post struct {
+	ID          string
+	AuthorID    string
+	Title       string
+	Slug        string
+	Excerpt     string
+	Content     string
+	Language    string
+	Status      Status
+	IsPublished bool
+	PublishedAt time.Time
+	CreatedDate time.Time
+	LastUpdated time.Time
+}
+
+// CreatePost creates a new post and returns it.
+func CreatePost(authorID, title, excerpt, content string, isPublished bool) *Post {
+	now := time.Now()
+	post := Post{
+		ID:          uuid.NewString(),
+		AuthorID:    authorID,
+		Title:       title,
+		Slug:        slug.Make(title),
+		Excerpt:     excerpt,
+		Content:     content,
+		Language:    DefaultLanguage,
+		Status:      StatusDraft,
+		PublishedAt: now,
+		CreatedDate: now,
+		LastUpdated: now,
+	}
+
+	if isPublished {
+		post.setStatus(StatusPublished)
+	}
+
+	if post.Excerpt == "" {
+		post.Excerpt = MakeExcerpt(content)
+	}
+
+	return &post
+}
+
+// Update changes the post's title, excerpt and content.
+// the slug is left as it is, see ChangeSlug.
+func (p *Post) Update(title, excerpt, content string) {
+	p.Title = title
+	p.Excerpt = excerpt
+	if p.Excerpt == "" {
+		p.Excerpt = MakeExcerpt(content)
+	}
+
+	p.Content = content
+	p.LastUpdated = time.Now()
+}
+
+// ChangeLanguage changes the language the post is stemmed in for the search.
+// an empty language leaves it as it is.
+func (p *Post) ChangeLanguage(lang string) {
+	if lang != "" {
+		p.Language = lang
+	}
+}
+
+// Publish publishes a draft or scheduled post right away.
+func (p *Post) Publish() error {
+	if p.Status != StatusDraft && p.Status != StatusScheduled {
+		return ErrInvalidTransition
+	}
+
+	p.PublishedAt = time.Now()
+	p.setStatus(StatusPublished)
+	return nil
+}
+
+// Schedule schedules a draft post to be published at the given time.
+// a scheduled post can be rescheduled.
+func (p *Post) Schedule(at time.Time) error {
+	if p.Status != StatusDraft && p.Status != StatusScheduled {
+		return ErrInvalidTransition
+	}
+	if !at.After(time.Now()) {
+		return ErrScheduleInPast
+	}
+
+	// timestamps are stored without a time zone, keep the server's one.
+	p.PublishedAt = at.Local()
+	p.setStatus(StatusScheduled)
+	return nil
+}
+
+// Unpublish moves a scheduled, published or archived post back to draft.
+func (p *Post) Unpublish() error {
+	if p.Status == StatusDraft {
+		return ErrInvalidTransition
+	}
+
+	p.setStatus(StatusDraft)
+	return nil
+}
+
+// Archive hides a post from the readers without deleting it.
+func (p *Post) Archive() error {
+	if p.Status == StatusArchived {
+		return ErrInvalidTransition
+	}
+
+	p.setStatus(StatusArchived)
+	return nil
+}
+
+// setStatus changes the status and keeps IsPublished in sync with it.
+func (p *Post) setStatus(status Status) {
+	p.Status = status
+	p.IsPublished = status == StatusPublished
+	p.LastUpdated = time.Now()
+}
+
+// ChangeSlug sets a new slug and returns the previous one.
+func (p *Post) ChangeSlug(newSlug string) string {
+	oldSlug := p.Slug
+	p.Slug = newSlug
+	return oldSlug
+}
+
+// IsAuthoredBy reports whether the post belongs to the given user.
+func (p *Post) IsAuthoredBy(userID string) bool {
+	return p.AuthorID == userID
+}
+
+// MakeExcerpt takes the first characters of the content as an excerpt.
+func MakeExcerpt(content string) string {
+	runes := []rune(strings.TrimSpace(content))
+	if len(runes) <= excerptLength {
+		return string(runes)
+	}
+	return string(runes[:excerptLength-3]) + "..."
+}
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"github.com/SemmiDev/blog/internal/common/slug"
+	"github.com/google/uuid"
+)
+
+// Term represents a row of the tags or categories table.
+// both taxonomies share the same shape, a unique name and a unique slug.
+type Term struct {
+	ID   string
+	Name string
+	Slug string
+}
+
+// CreateTerm creates a new tag or category and returns it.
+func CreateTerm(name string) *Term {
+	return &Term{
+		ID:   uuid.NewString(),
+		Name: name,
+		Slug: slug.Make(name),
+	}
+}
@@ -0,0 +1,187 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const postColumns = `posts.id, posts.author_id, posts.title, posts.slug, posts.excerpt, posts.content, posts.language::text, posts.status,
+	posts.is_published, posts.published_at, posts.created_at, posts.updated_at,
+	(SELECT COUNT(*) FROM favorites WHERE favorites.post_id = posts.id)`
+
+type PostQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostQueryPostgresql(DB *pgxpool.Pool) *PostQueryPostgresql {
+	return &PostQueryPostgresql{DB: DB}
+}
+
+func (p PostQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	return p.findOne(ctx, `SELECT `+postColumns+` FROM posts WHERE id = $1`, id)
+}
+
+func (p PostQueryPostgresql) FindBySlug(ctx context.Context, slug string) <-chan query.Result {
+	return p.findOne(ctx, `SELECT `+postColumns+` FROM posts WHERE slug = $1`, slug)
+}
+
+func (p PostQueryPostgresql) FindByOldSlug(ctx context.Context, slug string) <-chan query.Result {
+	return p.findOne(ctx, `SELECT `+postColumns+` FROM posts
+		JOIN post_slugs ON post_slugs.post_id = posts.id
+		WHERE post_slugs.slug = $1`, slug)
+}
+
+func (p PostQueryPostgresql) SlugExists(ctx context.Context, slug, excludeID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		exists := false
+		err := p.DB.QueryRow(ctx, `SELECT
+			EXISTS (SELECT 1 FROM posts WHERE slug = $1 AND id <> $2) OR
+			EXISTS (SELECT 1 FROM post_slugs WHERE slug = $1 AND post_id <> $2)`, slug, excludeID).Scan(&exists)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: exists}
+	}()
+
+	return result
+}
+
+func (p PostQueryPostgresql) FindPublished(ctx context.Context, limit, offset int) <-chan query.Result {
+	return p.findMany(ctx, `SELECT `+postColumns+`, COUNT(*) OVER() FROM posts
+		WHERE status = 'published'
+		ORDER BY published_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset)
+}
+
+func (p PostQueryPostgresql) FindByAuthor(ctx context.Context, authorID string, limit, offset int) <-chan query.Result {
+	return p.findMany(ctx, `SELECT `+postColumns+`, COUNT(*) OVER() FROM posts
+		WHERE author_id = $3
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset, authorID)
+}
+
+func (p PostQueryPostgresql) FindPublishedByTag(ctx context.Context, slug string, limit, offset int) <-chan query.Result {
+	return p.findMany(ctx, `SELECT `+postColumns+`, COUNT(*) OVER() FROM posts
+		WHERE status = 'published' AND id IN (
+			SELECT posts_tags.post_id FROM posts_tags
+			JOIN tags ON tags.id = posts_tags.tag_id
+			WHERE tags.slug = $3)
+		ORDER BY published_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset, slug)
+}
+
+func (p PostQueryPostgresql) FindPublishedByCategory(ctx context.Context, slug string, limit, offset int) <-chan query.Result {
+	return p.findMany(ctx, `SELECT `+postColumns+`, COUNT(*) OVER() FROM posts
+		WHERE status = 'published' AND id IN (
+			SELECT posts_categories.post_id FROM posts_categories
+			JOIN categories ON categories.id = posts_categories.category_id
+			WHERE categories.slug = $3)
+		ORDER BY published_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset, slug)
+}
+
+// FindFavoritedBy returns the published posts favorited by the user,
+// the most recently favorited first.
+func (p PostQueryPostgresql) FindFavoritedBy(ctx context.Context, userID string, limit, offset int) <-chan query.Result {
+	return p.findMany(ctx, `SELECT `+postColumns+`, COUNT(*) OVER() FROM posts
+		JOIN favorites ON favorites.post_id = posts.id
+		WHERE favorites.user_id = $3 AND posts.status = 'published'
+		ORDER BY favorites.created_at DESC
+		LIMIT $1 OFFSET $2`, limit, offset, userID)
+}
+
+func (p PostQueryPostgresql) findOne(ctx context.Context, sql string, args ...interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		post := storage.Post{}
+		err := p.DB.QueryRow(ctx, sql, args...).Scan(
+			&post.ID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Slug,
+			&post.Excerpt,
+			&post.Content,
+			&post.Language,
+			&post.Status,
+			&post.IsPublished,
+			&post.PublishedAt,
+			&post.CreatedDate,
+			&post.LastUpdated,
+			&post.FavoriteCount,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: post}
+	}()
+
+	return result
+}
+
+func (p PostQueryPostgresql) findMany(ctx context.Context, sql string, args ...interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := p.DB.Query(ctx, sql, args...)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		list := storage.PostList{Posts: []storage.Post{}}
+		for rows.Next() {
+			post := storage.Post{}
+			err := rows.Scan(
+				&post.ID,
+				&post.AuthorID,
+				&post.Title,
+				&post.Slug,
+				&post.Excerpt,
+				&post.Content,
+				&post.Language,
+				&post.Status,
+				&post.IsPublished,
+				&post.PublishedAt,
+				&post.CreatedDate,
+				&post.LastUpdated,
+				&post.FavoriteCount,
+				&list.Total,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			list.Posts = append(list.Posts, post)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: list}
+	}()
+
+	return result
+}
@@ -0,0 +1,76 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// headlineOptions wraps the matching words of a snippet in <mark>.
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+type SearchQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewSearchQueryPostgresql(DB *pgxpool.Pool) *SearchQueryPostgresql {
+	return &SearchQueryPostgresql{DB: DB}
+}
+
+// Search matches posts.search_vector, which is generated from the title,
+// excerpt and content of the post, along with posts.tags_vector, which
+// triggers keep up to date with the names of the tags of the post.
+// a tag match weighs as much as a title match, both are matched through one index.
+func (p SearchQueryPostgresql) Search(ctx context.Context, q, language string, limit, offset int) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := p.DB.Query(ctx, `SELECT posts.id, posts.slug, posts.published_at,
+				ts_rank_cd(posts.search_vector || posts.tags_vector, search.query) AS rank,
+				ts_headline(posts.language, posts.title, search.query, 'HighlightAll=true'),
+				ts_headline(posts.language, posts.excerpt || ' ' || posts.content, search.query, $5),
+				COUNT(*) OVER()
+			FROM posts
+			CROSS JOIN (SELECT websearch_to_tsquery($1::text::regconfig, $2) AS query) AS search
+			WHERE posts.status = 'published'
+				AND posts.language = $1::text::regconfig
+				AND (posts.search_vector || posts.tags_vector) @@ search.query
+			ORDER BY rank DESC, posts.published_at DESC
+			LIMIT $3 OFFSET $4`, language, q, limit, offset, headlineOptions)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		list := storage.SearchList{Results: []storage.SearchResult{}}
+		for rows.Next() {
+			searchResult := storage.SearchResult{}
+			err := rows.Scan(
+				&searchResult.ID,
+				&searchResult.Slug,
+				&searchResult.PublishedAt,
+				&searchResult.Rank,
+				&searchResult.Title,
+				&searchResult.Snippet,
+				&list.Total,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			list.Results = append(list.Results, searchResult)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: list}
+	}()
+
+	return result
+}
@@ -0,0 +1,104 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TermQueryPostgresql reads the tags or the categories table,
+// depending on how it has been constructed.
+type TermQueryPostgresql struct {
+	DB        *pgxpool.Pool
+	table     string
+	joinTable string
+	joinKey   string
+}
+
+func NewTagQueryPostgresql(DB *pgxpool.Pool) *TermQueryPostgresql {
+	return &TermQueryPostgresql{DB: DB, table: "tags", joinTable: "posts_tags", joinKey: "tag_id"}
+}
+
+func NewCategoryQueryPostgresql(DB *pgxpool.Pool) *TermQueryPostgresql {
+	return &TermQueryPostgresql{DB: DB, table: "categories", joinTable: "posts_categories", joinKey: "category_id"}
+}
+
+// FindAll returns every term with the number of published posts linked to it.
+func (t TermQueryPostgresql) FindAll(ctx context.Context) <-chan query.Result {
+	return t.findMany(ctx, `SELECT t.id, t.name, t.slug, COUNT(p.id) FROM `+t.table+` t
+		LEFT JOIN `+t.joinTable+` j ON j.`+t.joinKey+` = t.id
+		LEFT JOIN posts p ON p.id = j.post_id AND p.status = 'published'
+		GROUP BY t.id, t.name, t.slug
+		ORDER BY COUNT(p.id) DESC, t.name`)
+}
+
+func (t TermQueryPostgresql) FindByPost(ctx context.Context, postID string) <-chan query.Result {
+	return t.findMany(ctx, `SELECT t.id, t.name, t.slug, 0 FROM `+t.table+` t
+		JOIN `+t.joinTable+` j ON j.`+t.joinKey+` = t.id
+		WHERE j.post_id = $1
+		ORDER BY t.name`, postID)
+}
+
+func (t TermQueryPostgresql) FindBySlug(ctx context.Context, slug string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		term := storage.Term{}
+		err := t.DB.QueryRow(ctx, `SELECT id, name, slug FROM `+t.table+` WHERE slug = $1`, slug).Scan(
+			&term.ID,
+			&term.Name,
+			&term.Slug,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrTermNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: term}
+	}()
+
+	return result
+}
+
+func (t TermQueryPostgresql) findMany(ctx context.Context, sql string, args ...interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := t.DB.Query(ctx, sql, args...)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		terms := []storage.Term{}
+		for rows.Next() {
+			term := storage.Term{}
+			err := rows.Scan(&term.ID, &term.Name, &term.Slug, &term.PostCount)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			terms = append(terms, term)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: terms}
+	}()
+
+	return result
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when the requested post does not exist.
+	ErrNotFound = errors.New("post not found")
+	// ErrTermNotFound is returned when the requested tag or category does not exist.
+	ErrTermNotFound = errors.New("term not found")
+)
+
+type PostQuery interface {
+	FindByID(ctx context.Context, id string) <-chan Result
+	FindBySlug(ctx context.Context, slug string) <-chan Result
+	// FindByOldSlug returns the post that has been renamed from the slug.
+	FindByOldSlug(ctx context.Context, slug string) <-chan Result
+	// SlugExists reports whether a post, other than excludeID, uses or used the slug.
+	SlugExists(ctx context.Context, slug, excludeID string) <-chan Result
+	FindPublished(ctx context.Context, limit, offset int) <-chan Result
+	FindByAuthor(ctx context.Context, authorID string, limit, offset int) <-chan Result
+	FindPublishedByTag(ctx context.Context, slug string, limit, offset int) <-chan Result
+	FindPublishedByCategory(ctx context.Context, slug string, limit, offset int) <-chan Result
+	FindFavoritedBy(ctx context.Context, userID string, limit, offset int) <-chan Result
+}
+
+// SearchQuery searches the published posts.
+type SearchQuery interface {
+	// Search returns the published posts written in the language that match
+	// the web search style q in their title, excerpt, content or tags,
+	// the most relevant first.
+	Search(ctx context.Context, q, language string, limit, offset int) <-chan Result
+}
+
+// TermQuery reads tags or categories.
+type TermQuery interface {
+	FindAll(ctx context.Context) <-chan Result
+	FindBySlug(ctx context.Context, slug string) <-chan Result
+	FindByPost(ctx context.Context, postID string) <-chan Result
+}
+
+type Result struct {
+	Result interface{}
+	Error  error
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"time"
+)
+
+// ErrSlugExists is returned when another post already uses the slug.
+var ErrSlugExists = errors.New("slug already exists")
+
+type PostCommand interface {
+	PostSaver
+	PostUpdater
+	PostDeleter
+	PostPublisher
+}
+
+type PostSaver interface {
+	Save(ctx context.Context, arg *entity.Post) <-chan error
+}
+
+type PostUpdater interface {
+	Update(ctx context.Context, arg *entity.Post) <-chan error
+	// SaveOldSlug keeps a previous slug of the post for redirects.
+	SaveOldSlug(ctx context.Context, postID, slug string) <-chan error
+}
+
+type PostDeleter interface {
+	Delete(ctx context.Context, id string) <-chan error
+}
+
+// PostPublisher publishes the scheduled posts whose time has come.
+// the result holds the number of published posts.
+type PostPublisher interface {
+	PublishScheduled(ctx context.Context, now time.Time) <-chan Result
+}
+
+// TermCommand writes tags or categories and links them to posts.
+type TermCommand interface {
+	// Save inserts the term, it does nothing when the name or slug already exists.
+	Save(ctx context.Context, arg *entity.Term) <-chan error
+	Attach(ctx context.Context, postID, termID string) <-chan error
+	Detach(ctx context.Context, postID, termID string) <-chan error
+}
+
+// FavoriteCommand writes the favorites of the users.
+type FavoriteCommand interface {
+	// Save favorites the post, it does nothing when it is already a favorite.
+	Save(ctx context.Context, arg *entity.Favorite) <-chan error
+	Delete(ctx context.Context, userID, postID string) <-chan error
+}
+
+type Result struct {
+	Result interface{}
+	Error  error
+}
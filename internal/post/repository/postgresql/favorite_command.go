@@ -0,0 +1,42 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type FavoriteCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewFavoriteCommandPostgresql(DB *pgxpool.Pool) *FavoriteCommandPostgresql {
+	return &FavoriteCommandPostgresql{DB: DB}
+}
+
+func (f *FavoriteCommandPostgresql) Save(ctx context.Context, arg *entity.Favorite) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := f.DB.Exec(ctx, `INSERT INTO favorites (id, user_id, post_id, created_at) VALUES ($1, $2, $3, $4)
+			ON CONFLICT (user_id, post_id) DO NOTHING`, arg.ID, arg.UserID, arg.PostID, arg.CreatedDate)
+		result <- err
+	}()
+
+	return result
+}
+
+func (f *FavoriteCommandPostgresql) Delete(ctx context.Context, userID, postID string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := f.DB.Exec(ctx, `DELETE FROM favorites WHERE user_id = $1 AND post_id = $2`, userID, postID)
+		result <- err
+	}()
+
+	return result
+}
@@ -0,0 +1,113 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/repository"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type PostCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewPostCommandPostgresql(DB *pgxpool.Pool) *PostCommandPostgresql {
+	return &PostCommandPostgresql{DB: DB}
+}
+
+func (p *PostCommandPostgresql) Save(ctx context.Context, arg *entity.Post) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := p.DB.Exec(ctx, `INSERT INTO posts
+			(id, author_id, title, slug, excerpt, content, language, status, is_published, published_at, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7::text::regconfig, $8, $9, $10, $11, $12)`,
+			arg.ID, arg.AuthorID, arg.Title, arg.Slug, arg.Excerpt, arg.Content, arg.Language,
+			arg.Status, arg.IsPublished, arg.PublishedAt, arg.CreatedDate, arg.LastUpdated)
+
+		result <- slugError(err)
+	}()
+
+	return result
+}
+
+func (p *PostCommandPostgresql) Update(ctx context.Context, arg *entity.Post) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := p.DB.Exec(ctx, `UPDATE posts
+			SET title = $2, slug = $3, excerpt = $4, content = $5, language = $6::text::regconfig,
+				status = $7, is_published = $8, published_at = $9, updated_at = $10
+			WHERE id = $1`,
+			arg.ID, arg.Title, arg.Slug, arg.Excerpt, arg.Content, arg.Language,
+			arg.Status, arg.IsPublished, arg.PublishedAt, arg.LastUpdated)
+
+		result <- slugError(err)
+	}()
+
+	return result
+}
+
+func (p *PostCommandPostgresql) SaveOldSlug(ctx context.Context, postID, slug string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := p.DB.Exec(ctx, `INSERT INTO post_slugs (slug, post_id) VALUES ($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET post_id = EXCLUDED.post_id, created_at = NOW()`, slug, postID)
+		result <- err
+	}()
+
+	return result
+}
+
+func (p *PostCommandPostgresql) Delete(ctx context.Context, id string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := p.DB.Exec(ctx, `DELETE FROM posts WHERE id = $1`, id)
+		result <- err
+	}()
+
+	return result
+}
+
+func (p *PostCommandPostgresql) PublishScheduled(ctx context.Context, now time.Time) <-chan repository.Result {
+	result := make(chan repository.Result)
+
+	go func() {
+		defer close(result)
+
+		tag, err := p.DB.Exec(ctx, `UPDATE posts
+			SET status = 'published', is_published = TRUE, updated_at = $1
+			WHERE status = 'scheduled' AND published_at <= $1`, now)
+		if err != nil {
+			result <- repository.Result{Error: err}
+			return
+		}
+
+		result <- repository.Result{Result: tag.RowsAffected()}
+	}()
+
+	return result
+}
+
+// slugError turns the unique violation of the slug, when another post
+// has taken it in the meantime, into repository.ErrSlugExists.
+func slugError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "posts_slug_key" {
+		return repository.ErrSlugExists
+	}
+	return err
+}
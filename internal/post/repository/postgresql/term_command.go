@@ -0,0 +1,66 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TermCommandPostgresql writes the tags or the categories table,
+// depending on how it has been constructed.
+type TermCommandPostgresql struct {
+	DB        *pgxpool.Pool
+	table     string
+	joinTable string
+	joinKey   string
+}
+
+func NewTagCommandPostgresql(DB *pgxpool.Pool) *TermCommandPostgresql {
+	return &TermCommandPostgresql{DB: DB, table: "tags", joinTable: "posts_tags", joinKey: "tag_id"}
+}
+
+func NewCategoryCommandPostgresql(DB *pgxpool.Pool) *TermCommandPostgresql {
+	return &TermCommandPostgresql{DB: DB, table: "categories", joinTable: "posts_categories", joinKey: "category_id"}
+}
+
+func (t *TermCommandPostgresql) Save(ctx context.Context, arg *entity.Term) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := t.DB.Exec(ctx, `INSERT INTO `+t.table+` (id, name, slug) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`, arg.ID, arg.Name, arg.Slug)
+		result <- err
+	}()
+
+	return result
+}
+
+func (t *TermCommandPostgresql) Attach(ctx context.Context, postID, termID string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := t.DB.Exec(ctx, `INSERT INTO `+t.joinTable+` (post_id, `+t.joinKey+`) VALUES ($1, $2)
+			ON CONFLICT DO NOTHING`, postID, termID)
+		result <- err
+	}()
+
+	return result
+}
+
+func (t *TermCommandPostgresql) Detach(ctx context.Context, postID, termID string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := t.DB.Exec(ctx, `DELETE FROM `+t.joinTable+` WHERE post_id = $1 AND `+t.joinKey+` = $2`,
+			postID, termID)
+		result <- err
+	}()
+
+	return result
+}
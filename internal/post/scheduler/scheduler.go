@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/common/logger"
+	"github.com/SemmiDev/blog/internal/post/repository"
+	"time"
+)
+
+// Scheduler periodically publishes the scheduled posts whose time has come.
+type Scheduler struct {
+	publisher repository.PostPublisher
+	interval  time.Duration
+	done      chan struct{}
+}
+
+// New creates a new Scheduler and starts it in the background.
+func New(publisher repository.PostPublisher) *Scheduler {
+	scheduler := &Scheduler{
+		publisher: publisher,
+		interval:  30 * time.Second,
+		done:      make(chan struct{}),
+	}
+	go scheduler.run()
+	return scheduler
+}
+
+func (s *Scheduler) Close() error {
+	s.done <- struct{}{}
+	return nil
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case t := <-ticker.C:
+			result := <-s.publisher.PublishScheduled(context.Background(), t)
+			if result.Error != nil {
+				logger.Log.Error().Interface("scheduler", result.Error.Error()).Send()
+				continue
+			}
+			if count, _ := result.Result.(int64); count > 0 {
+				logger.Log.Info().Int64("published", count).Msg("scheduled posts published")
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	userServer "github.com/SemmiDev/blog/internal/user/server"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// FindFavoritePostsHandler returns the reading list of the authenticated user page by page.
+func (s *PostServer) FindFavoritePostsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	posts, err := s.FavoriteService.FindFavoritePosts(c.Context(), page.Page, page.Limit, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": posts,
+	})
+}
+
+// FavoritePostHandler adds a post to the favorites of the authenticated user.
+func (s *PostServer) FavoritePostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.FavoriteService.FavoritePost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// UnfavoritePostHandler removes a post from the favorites of the authenticated user.
+func (s *PostServer) UnfavoritePostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.FavoriteService.UnfavoritePost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
@@ -0,0 +1,259 @@
+package server
+
+import (
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	queryPostgresql "github.com/SemmiDev/blog/internal/post/query/postgresql"
+	commandPostgresql "github.com/SemmiDev/blog/internal/post/repository/postgresql"
+	"github.com/SemmiDev/blog/internal/post/scheduler"
+	"github.com/SemmiDev/blog/internal/post/service"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	userQueryPostgresql "github.com/SemmiDev/blog/internal/user/query/postgresql"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	userServer "github.com/SemmiDev/blog/internal/user/server"
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PostServer is the struct that contains PostService, TaxonomyService,
+// FavoriteService and SearchService. it will be used to interact with the services.
+type PostServer struct {
+	PostService     service.PostService
+	TaxonomyService service.TaxonomyService
+	FavoriteService service.FavoriteService
+	SearchService   service.SearchService
+}
+
+// NewPostServer creates a new PostServer.
+func NewPostServer(db *pgxpool.Pool) (*PostServer, error) {
+	postCommand := commandPostgresql.NewPostCommandPostgresql(db)
+
+	// publishes the scheduled posts in the background.
+	// every instance runs one, publishing a post twice is harmless.
+	scheduler.New(postCommand)
+
+	postServiceImpl := &service.PostServiceImpl{
+		PostQuery:       queryPostgresql.NewPostQueryPostgresql(db),
+		PostCommand:     postCommand,
+		TagQuery:        queryPostgresql.NewTagQueryPostgresql(db),
+		TagCommand:      commandPostgresql.NewTagCommandPostgresql(db),
+		CategoryQuery:   queryPostgresql.NewCategoryQueryPostgresql(db),
+		CategoryCommand: commandPostgresql.NewCategoryCommandPostgresql(db),
+		FavoriteCommand: commandPostgresql.NewFavoriteCommandPostgresql(db),
+		SearchQuery:     queryPostgresql.NewSearchQueryPostgresql(db),
+		UserQuery:       userQueryPostgresql.NewUserQueryPostgresql(db),
+	}
+
+	return &PostServer{
+		PostService:     postServiceImpl,
+		TaxonomyService: postServiceImpl,
+		FavoriteService: postServiceImpl,
+		SearchService:   postServiceImpl,
+	}, nil
+}
+
+// Mount mounts the post server to the fiber app.
+// authMiddleware guards the routes that need an authenticated author.
+func (s *PostServer) Mount(r fiber.Router, authMiddleware fiber.Handler) {
+	r.Get("/", s.FindPublishedPostsHandler)
+	r.Get("/me", authMiddleware, s.FindMyPostsHandler)
+	r.Get("/favorites", authMiddleware, s.FindFavoritePostsHandler)
+	r.Get("/:slug", s.FindPostBySlugHandler)
+
+	// readers can only comment and favorite the posts.
+	writer := userServer.RequirePermission(rbac.PermissionWritePosts)
+
+	r.Post("/", authMiddleware, writer, s.CreatePostHandler)
+	r.Put("/:id", authMiddleware, writer, s.UpdatePostHandler)
+	r.Delete("/:id", authMiddleware, writer, s.DeletePostHandler)
+
+	r.Put("/:id/publish", authMiddleware, writer, s.PublishPostHandler)
+	r.Put("/:id/schedule", authMiddleware, writer, s.SchedulePostHandler)
+	r.Put("/:id/unpublish", authMiddleware, writer, s.UnpublishPostHandler)
+	r.Put("/:id/archive", authMiddleware, writer, s.ArchivePostHandler)
+
+	r.Post("/:id/tags", authMiddleware, writer, s.AttachTagHandler)
+	r.Delete("/:id/tags/:slug", authMiddleware, writer, s.DetachTagHandler)
+	r.Post("/:id/categories", authMiddleware, writer, s.AttachCategoryHandler)
+	r.Delete("/:id/categories/:slug", authMiddleware, writer, s.DetachCategoryHandler)
+
+	r.Post("/:id/favorite", authMiddleware, s.FavoritePostHandler)
+	r.Delete("/:id/favorite", authMiddleware, s.UnfavoritePostHandler)
+}
+
+// FindPublishedPostsHandler returns the published posts page by page.
+func (s *PostServer) FindPublishedPostsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	posts, err := s.PostService.FindPublishedPosts(c.Context(), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": posts,
+	})
+}
+
+// FindMyPostsHandler returns the posts of the authenticated user,
+// drafts included.
+func (s *PostServer) FindMyPostsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	posts, err := s.PostService.FindMyPosts(c.Context(), page.Page, page.Limit, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": posts,
+	})
+}
+
+// FindPostBySlugHandler returns a published post by its slug.
+// if the post has been renamed, it redirects to the new slug.
+func (s *PostServer) FindPostBySlugHandler(c *fiber.Ctx) error {
+	slug := c.Params("slug")
+
+	post, err := s.PostService.FindPostBySlug(c.Context(), slug)
+	// only a post that is not found may have been renamed, the redirect is cached for good.
+	if errors.Is(err, helper.NewErr(helper.ErrNotFoundCode, "post")) {
+		newSlug, resolveErr := s.PostService.ResolveSlug(c.Context(), slug)
+		if resolveErr == nil {
+			return c.Redirect(strings.TrimSuffix(c.Path(), slug)+newSlug, http.StatusMovedPermanently)
+		}
+	}
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// CreatePostHandler creates a new post authored by the authenticated user.
+func (s *PostServer) CreatePostHandler(c *fiber.Ctx) error {
+	title := c.FormValue("title")
+	excerpt := c.FormValue("excerpt")
+	content := c.FormValue("content")
+	language := c.FormValue("language")
+	isPublished := c.FormValue("is_published") == "true"
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.CreatePost(c.Context(), title, excerpt, content, language, isPublished, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// UpdatePostHandler updates a post of the authenticated user.
+func (s *PostServer) UpdatePostHandler(c *fiber.Ctx) error {
+	title := c.FormValue("title")
+	excerpt := c.FormValue("excerpt")
+	content := c.FormValue("content")
+	language := c.FormValue("language")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.UpdatePost(c.Context(), c.Params("id"), title, excerpt, content, language, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// DeletePostHandler deletes a post of the authenticated user.
+func (s *PostServer) DeletePostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	err := s.PostService.DeletePost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// PublishPostHandler publishes a post of the authenticated user right away.
+func (s *PostServer) PublishPostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.PublishPost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// SchedulePostHandler schedules a post of the authenticated user.
+// publish_at must be an RFC 3339 time in the future.
+func (s *PostServer) SchedulePostHandler(c *fiber.Ctx) error {
+	publishAt, err := time.Parse(time.RFC3339, c.FormValue("publish_at"))
+	if err != nil {
+		return helper.Error(c, helper.NewErr(helper.ErrParseCode, "publish_at"))
+	}
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.SchedulePost(c.Context(), c.Params("id"), publishAt, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// UnpublishPostHandler moves a post of the authenticated user back to draft.
+func (s *PostServer) UnpublishPostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.UnpublishPost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
+
+// ArchivePostHandler archives a post of the authenticated user.
+func (s *PostServer) ArchivePostHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	post, err := s.PostService.ArchivePost(c.Context(), c.Params("id"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": post,
+	})
+}
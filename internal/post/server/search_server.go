@@ -0,0 +1,29 @@
+package server
+
+import (
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// MountSearch mounts the search routes to the fiber router.
+func (s *PostServer) MountSearch(r fiber.Router) {
+	r.Get("/", s.SearchPostsHandler)
+}
+
+// SearchPostsHandler searches the published posts page by page.
+// q supports quoted phrases, "or" and -excluded words,
+// lang is the language the posts are written in.
+func (s *PostServer) SearchPostsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	results, err := s.SearchService.SearchPosts(c.Context(), c.Query("q"), c.Query("lang"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": results,
+	})
+}
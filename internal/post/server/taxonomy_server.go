@@ -0,0 +1,133 @@
+package server
+
+import (
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	userServer "github.com/SemmiDev/blog/internal/user/server"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// MountTags mounts the tag routes to the fiber app.
+func (s *PostServer) MountTags(r fiber.Router) {
+	r.Get("/", s.FindTagsHandler)
+	r.Get("/:slug/posts", s.FindPostsByTagHandler)
+}
+
+// MountCategories mounts the category routes to the fiber app.
+func (s *PostServer) MountCategories(r fiber.Router) {
+	r.Get("/", s.FindCategoriesHandler)
+	r.Get("/:slug/posts", s.FindPostsByCategoryHandler)
+}
+
+// FindTagsHandler returns every tag with its number of published posts.
+func (s *PostServer) FindTagsHandler(c *fiber.Ctx) error {
+	tags, err := s.TaxonomyService.FindTags(c.Context())
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": tags,
+	})
+}
+
+// FindCategoriesHandler returns every category with its number of published posts.
+func (s *PostServer) FindCategoriesHandler(c *fiber.Ctx) error {
+	categories, err := s.TaxonomyService.FindCategories(c.Context())
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": categories,
+	})
+}
+
+// FindPostsByTagHandler returns the published posts of a tag page by page.
+func (s *PostServer) FindPostsByTagHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	posts, err := s.TaxonomyService.FindPostsByTag(c.Context(), c.Params("slug"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": posts,
+	})
+}
+
+// FindPostsByCategoryHandler returns the published posts of a category page by page.
+func (s *PostServer) FindPostsByCategoryHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	posts, err := s.TaxonomyService.FindPostsByCategory(c.Context(), c.Params("slug"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": posts,
+	})
+}
+
+// AttachTagHandler links a tag, by name, to a post of the authenticated user.
+func (s *PostServer) AttachTagHandler(c *fiber.Ctx) error {
+	name := c.FormValue("name")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	tag, err := s.TaxonomyService.AttachTag(c.Context(), c.Params("id"), name, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": tag,
+	})
+}
+
+// DetachTagHandler unlinks a tag from a post of the authenticated user.
+func (s *PostServer) DetachTagHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	err := s.TaxonomyService.DetachTag(c.Context(), c.Params("id"), c.Params("slug"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// AttachCategoryHandler links a category, by name, to a post of the authenticated user.
+func (s *PostServer) AttachCategoryHandler(c *fiber.Ctx) error {
+	name := c.FormValue("name")
+
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	category, err := s.TaxonomyService.AttachCategory(c.Context(), c.Params("id"), name, payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": category,
+	})
+}
+
+// DetachCategoryHandler unlinks a category from a post of the authenticated user.
+func (s *PostServer) DetachCategoryHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := userServer.AuthorizationPayload(c)
+
+	err := s.TaxonomyService.DetachCategory(c.Context(), c.Params("id"), c.Params("slug"), payload.Email)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
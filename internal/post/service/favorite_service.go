@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+)
+
+// FavoritePost adds a published post to the favorites of the user.
+func (s *PostServiceImpl) FavoritePost(ctx context.Context, postID, email string) (storage.Post, error) {
+	post, err := s.findStoredPost(ctx, postID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+	if !post.IsPublished {
+		return storage.Post{}, NewErr(ErrNotFoundCode, "post")
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	err = <-s.FavoriteCommand.Save(ctx, entity.CreateFavorite(user.ID, post.ID))
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	return s.findStoredPost(ctx, post.ID)
+}
+
+// UnfavoritePost removes a post from the favorites of the user.
+func (s *PostServiceImpl) UnfavoritePost(ctx context.Context, postID, email string) (storage.Post, error) {
+	post, err := s.findStoredPost(ctx, postID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	err = <-s.FavoriteCommand.Delete(ctx, user.ID, post.ID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	return s.findStoredPost(ctx, post.ID)
+}
+
+// FindFavoritePosts returns the reading list of the user, the most recently favorited first.
+func (s *PostServiceImpl) FindFavoritePosts(ctx context.Context, page, limit int, email string) (storage.PostList, error) {
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.PostList{}, err
+	}
+
+	return s.findPostList(s.PostQuery.FindFavoritedBy(ctx, user.ID, limit, (page-1)*limit), page, limit)
+}
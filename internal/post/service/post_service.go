@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	"github.com/SemmiDev/blog/internal/common/slug"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/repository"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	userQuery "github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"time"
+	"unicode/utf8"
+)
+
+// maxTitleLength is the maximum length of title and excerpt columns.
+const maxTitleLength = 100
+
+// reservedSlugs are the routes next to /posts/:slug, a post can not be read by them.
+var reservedSlugs = map[string]bool{
+	"me":        true,
+	"favorites": true,
+}
+
+// PostServiceImpl is a struct that implements PostService interface.
+type PostServiceImpl struct {
+	PostQuery       query.PostQuery
+	PostCommand     repository.PostCommand
+	TagQuery        query.TermQuery
+	TagCommand      repository.TermCommand
+	CategoryQuery   query.TermQuery
+	CategoryCommand repository.TermCommand
+	FavoriteCommand repository.FavoriteCommand
+	SearchQuery     query.SearchQuery
+	UserQuery       userQuery.UserQuery
+}
+
+// FindPostBySlug returns a published post by slug,
+// with its markdown content rendered to html.
+func (s *PostServiceImpl) FindPostBySlug(ctx context.Context, slug string) (storage.Post, error) {
+	result := <-s.PostQuery.FindBySlug(ctx, slug)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrNotFound) {
+			return storage.Post{}, NewErr(ErrNotFoundCode, "post")
+		}
+		return storage.Post{}, result.Error
+	}
+
+	post, ok := result.Result.(storage.Post)
+	if !ok {
+		return storage.Post{}, errors.New("helper type assertion")
+	}
+
+	// drafts, scheduled and archived posts are only
+	// visible to their author through FindMyPosts.
+	if !post.IsPublished {
+		return storage.Post{}, NewErr(ErrNotFoundCode, "post")
+	}
+
+	tags, err := s.findPostTerms(ctx, s.tags(), post.ID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	categories, err := s.findPostTerms(ctx, s.categories(), post.ID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	document, err := markdown.Render(post.Content)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	post.ContentHTML = document.HTML
+	post.TOC = document.TOC
+	post.Tags = tags
+	post.Categories = categories
+
+	return post, nil
+}
+
+// FindPublishedPosts returns published posts, newest first.
+func (s *PostServiceImpl) FindPublishedPosts(ctx context.Context, page, limit int) (storage.PostList, error) {
+	return s.findPostList(s.PostQuery.FindPublished(ctx, limit, (page-1)*limit), page, limit)
+}
+
+// FindMyPosts returns every post of the user, including drafts.
+func (s *PostServiceImpl) FindMyPosts(ctx context.Context, page, limit int, email string) (storage.PostList, error) {
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.PostList{}, err
+	}
+
+	return s.findPostList(s.PostQuery.FindByAuthor(ctx, user.ID, limit, (page-1)*limit), page, limit)
+}
+
+// CreatePost creates a new post authored by the user.
+// an empty language stems the post in entity.DefaultLanguage.
+func (s *PostServiceImpl) CreatePost(ctx context.Context, title, excerpt, content, language string, isPublished bool, email string) (storage.Post, error) {
+	err := validatePost(title, excerpt, content, language)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	post := entity.CreatePost(user.ID, title, excerpt, content, isPublished)
+	post.ChangeLanguage(language)
+	newSlug, err := s.uniqueSlug(ctx, post.Slug, post.ID)
+	if err != nil {
+		return storage.Post{}, err
+	}
+	post.ChangeSlug(newSlug)
+
+	err = <-s.PostCommand.Save(ctx, post)
+	if err != nil {
+		if errors.Is(err, repository.ErrSlugExists) {
+			return storage.Post{}, NewErr(ErrSlugExistsCode, "title")
+		}
+		return storage.Post{}, err
+	}
+
+	return toStorage(post), nil
+}
+
+// UpdatePost updates a post, only its author is allowed to do so.
+// an empty language keeps the language of the post.
+func (s *PostServiceImpl) UpdatePost(ctx context.Context, id, title, excerpt, content, language, email string) (storage.Post, error) {
+	err := validatePost(title, excerpt, content, language)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	post, err := s.findAuthoredPost(ctx, id, email)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	// a new title gives a new slug, the old one is kept
+	// so that its links redirect to the new one.
+	oldSlug := post.Slug
+	if title != post.Title {
+		newSlug, err := s.uniqueSlug(ctx, slug.Make(title), post.ID)
+		if err != nil {
+			return storage.Post{}, err
+		}
+		post.ChangeSlug(newSlug)
+	}
+
+	post.Update(title, excerpt, content)
+	post.ChangeLanguage(language)
+	err = <-s.PostCommand.Update(ctx, &post)
+	if err != nil {
+		if errors.Is(err, repository.ErrSlugExists) {
+			return storage.Post{}, NewErr(ErrSlugExistsCode, "title")
+		}
+		return storage.Post{}, err
+	}
+
+	// the old slug is only kept once the post no longer uses it,
+	// otherwise it would block the slug of the other posts.
+	if oldSlug != post.Slug {
+		err = <-s.PostCommand.SaveOldSlug(ctx, post.ID, oldSlug)
+		if err != nil {
+			return storage.Post{}, err
+		}
+	}
+
+	return s.findStoredPost(ctx, post.ID)
+}
+
+// DeletePost deletes a post, only its author is allowed to do so.
+func (s *PostServiceImpl) DeletePost(ctx context.Context, id, email string) error {
+	post, err := s.findAuthoredPost(ctx, id, email)
+	if err != nil {
+		return err
+	}
+
+	err = <-s.PostCommand.Delete(ctx, post.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ResolveSlug returns the current slug of the published post
+// that used to be reachable by the given slug.
+func (s *PostServiceImpl) ResolveSlug(ctx context.Context, oldSlug string) (string, error) {
+	result := <-s.PostQuery.FindByOldSlug(ctx, oldSlug)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrNotFound) {
+			return "", NewErr(ErrNotFoundCode, "post")
+		}
+		return "", result.Error
+	}
+
+	post, ok := result.Result.(storage.Post)
+	if !ok {
+		return "", errors.New("helper type assertion")
+	}
+
+	if !post.IsPublished {
+		return "", NewErr(ErrNotFoundCode, "post")
+	}
+
+	return post.Slug, nil
+}
+
+// PublishPost publishes a draft or scheduled post right away.
+func (s *PostServiceImpl) PublishPost(ctx context.Context, id, email string) (storage.Post, error) {
+	return s.changeStatus(ctx, id, email, func(post *entity.Post) error {
+		return post.Publish()
+	})
+}
+
+// SchedulePost schedules a post to be published by the scheduler at the given time.
+func (s *PostServiceImpl) SchedulePost(ctx context.Context, id string, at time.Time, email string) (storage.Post, error) {
+	return s.changeStatus(ctx, id, email, func(post *entity.Post) error {
+		return post.Schedule(at)
+	})
+}
+
+// UnpublishPost moves a post back to draft.
+func (s *PostServiceImpl) UnpublishPost(ctx context.Context, id, email string) (storage.Post, error) {
+	return s.changeStatus(ctx, id, email, func(post *entity.Post) error {
+		return post.Unpublish()
+	})
+}
+
+// ArchivePost archives a post.
+func (s *PostServiceImpl) ArchivePost(ctx context.Context, id, email string) (storage.Post, error) {
+	return s.changeStatus(ctx, id, email, func(post *entity.Post) error {
+		return post.Archive()
+	})
+}
+
+// changeStatus applies the transition to the post of the user and saves it.
+func (s *PostServiceImpl) changeStatus(ctx context.Context, id, email string, transition func(post *entity.Post) error) (storage.Post, error) {
+	post, err := s.findAuthoredPost(ctx, id, email)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	err = transition(&post)
+	if err != nil {
+		if errors.Is(err, entity.ErrScheduleInPast) {
+			return storage.Post{}, NewErr(ErrScheduleInPastCode, "publish_at")
+		}
+		if errors.Is(err, entity.ErrInvalidTransition) {
+			return storage.Post{}, NewErr(ErrInvalidStatusTransitionCode, "status")
+		}
+		return storage.Post{}, err
+	}
+
+	err = <-s.PostCommand.Update(ctx, &post)
+	if err != nil {
+		return storage.Post{}, err
+	}
+
+	return s.findStoredPost(ctx, post.ID)
+}
+
+// uniqueSlug returns base, or base with a numeric suffix, that neither
+// another post nor the slug history of another post uses, nor a route.
+func (s *PostServiceImpl) uniqueSlug(ctx context.Context, base, postID string) (string, error) {
+	return slug.Unique(base, func(candidate string) (bool, error) {
+		if reservedSlugs[candidate] {
+			return true, nil
+		}
+
+		result := <-s.PostQuery.SlugExists(ctx, candidate, postID)
+		if result.Error != nil {
+			return false, result.Error
+		}
+
+		exists, _ := result.Result.(bool)
+		return exists, nil
+	})
+}
+
+// findPostList waits for a list query and sets the page on it.
+func (s *PostServiceImpl) findPostList(queryResult <-chan query.Result, page, limit int) (storage.PostList, error) {
+	result := <-queryResult
+	if result.Error != nil {
+		return storage.PostList{}, result.Error
+	}
+
+	list, ok := result.Result.(storage.PostList)
+	if !ok {
+		return storage.PostList{}, errors.New("helper type assertion")
+	}
+
+	list.Page = page
+	list.Limit = limit
+	return list, nil
+}
+
+// findAuthoredPost returns the post if it is authored by the user with the email,
+// or if the role of the user allows editing the posts of every author.
+func (s *PostServiceImpl) findAuthoredPost(ctx context.Context, id, email string) (entity.Post, error) {
+	post, err := s.FindPostByID(ctx, id)
+	if err != nil {
+		return entity.Post{}, err
+	}
+
+	user, err := s.findUserByEmail(ctx, email)
+	if err != nil {
+		return entity.Post{}, err
+	}
+
+	if !post.IsAuthoredBy(user.ID) && !user.Role.Can(rbac.PermissionEditAnyPost) {
+		return entity.Post{}, NewErr(ErrForbiddenCode, "post")
+	}
+
+	return post, nil
+}
+
+// validatePost validates the fields of a post.
+func validatePost(title, excerpt, content, language string) error {
+	if title == "" || slug.Make(title) == "" {
+		return NewErr(ErrTitleEmptyCode, "title")
+	}
+	if utf8.RuneCountInString(title) > maxTitleLength {
+		return NewErr(ErrFieldTooLongCode, "title")
+	}
+	if utf8.RuneCountInString(excerpt) > maxTitleLength {
+		return NewErr(ErrFieldTooLongCode, "excerpt")
+	}
+	if content == "" {
+		return NewErr(ErrContentEmptyCode, "content")
+	}
+	if language != "" && !entity.IsLanguage(language) {
+		return NewErr(ErrUnsupportedLanguageCode, "language")
+	}
+	return nil
+}
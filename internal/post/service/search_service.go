@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSearchQueryLength is the maximum length of a search query.
+const maxSearchQueryLength = 200
+
+// SearchPosts searches the published posts written in the language,
+// an empty language searches the posts in entity.DefaultLanguage.
+func (s *PostServiceImpl) SearchPosts(ctx context.Context, q, language string, page, limit int) (storage.SearchList, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return storage.SearchList{}, NewErr(ErrSearchQueryEmptyCode, "q")
+	}
+	if utf8.RuneCountInString(q) > maxSearchQueryLength {
+		return storage.SearchList{}, NewErr(ErrFieldTooLongCode, "q")
+	}
+
+	if language == "" {
+		language = entity.DefaultLanguage
+	}
+	if !entity.IsLanguage(language) {
+		return storage.SearchList{}, NewErr(ErrUnsupportedLanguageCode, "lang")
+	}
+
+	result := <-s.SearchQuery.Search(ctx, q, language, limit, (page-1)*limit)
+	if result.Error != nil {
+		return storage.SearchList{}, result.Error
+	}
+
+	list, ok := result.Result.(storage.SearchList)
+	if !ok {
+		return storage.SearchList{}, errors.New("helper type assertion")
+	}
+
+	// the highlights are built from the raw title and markdown,
+	// only the <mark> elements added by the database are kept.
+	for i := range list.Results {
+		list.Results[i].Title = markdown.SanitizeHighlight(list.Results[i].Title)
+		list.Results[i].Snippet = markdown.SanitizeHighlight(list.Results[i].Snippet)
+	}
+
+	list.Query = q
+	list.Language = language
+	list.Page = page
+	list.Limit = limit
+	return list, nil
+}
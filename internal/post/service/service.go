@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	userStorage "github.com/SemmiDev/blog/internal/user/storage"
+	"time"
+)
+
+// PostService is a service for managing posts.
+type PostService interface {
+	FindPostByID(ctx context.Context, id string) (entity.Post, error)
+	FindPostBySlug(ctx context.Context, slug string) (storage.Post, error)
+	ResolveSlug(ctx context.Context, oldSlug string) (string, error)
+	FindPublishedPosts(ctx context.Context, page, limit int) (storage.PostList, error)
+	FindMyPosts(ctx context.Context, page, limit int, email string) (storage.PostList, error)
+	CreatePost(ctx context.Context, title, excerpt, content, language string, isPublished bool, email string) (storage.Post, error)
+	UpdatePost(ctx context.Context, id, title, excerpt, content, language, email string) (storage.Post, error)
+	DeletePost(ctx context.Context, id, email string) error
+	PublishPost(ctx context.Context, id, email string) (storage.Post, error)
+	SchedulePost(ctx context.Context, id string, at time.Time, email string) (storage.Post, error)
+	UnpublishPost(ctx context.Context, id, email string) (storage.Post, error)
+	ArchivePost(ctx context.Context, id, email string) (storage.Post, error)
+}
+
+// TaxonomyService is a service for managing tags and categories of posts.
+type TaxonomyService interface {
+	FindTags(ctx context.Context) ([]storage.Term, error)
+	FindCategories(ctx context.Context) ([]storage.Term, error)
+	FindPostsByTag(ctx context.Context, slug string, page, limit int) (storage.PostList, error)
+	FindPostsByCategory(ctx context.Context, slug string, page, limit int) (storage.PostList, error)
+	AttachTag(ctx context.Context, postID, name, email string) (storage.Term, error)
+	DetachTag(ctx context.Context, postID, slug, email string) error
+	AttachCategory(ctx context.Context, postID, name, email string) (storage.Term, error)
+	DetachCategory(ctx context.Context, postID, slug, email string) error
+}
+
+// FavoriteService is a service for managing the favorite posts of users.
+type FavoriteService interface {
+	FavoritePost(ctx context.Context, postID, email string) (storage.Post, error)
+	UnfavoritePost(ctx context.Context, postID, email string) (storage.Post, error)
+	FindFavoritePosts(ctx context.Context, page, limit int, email string) (storage.PostList, error)
+}
+
+// SearchService is a service for the full-text search over published posts.
+type SearchService interface {
+	SearchPosts(ctx context.Context, q, language string, page, limit int) (storage.SearchList, error)
+}
+
+// FindPostByID returns a post by id.
+func (s PostServiceImpl) FindPostByID(ctx context.Context, id string) (entity.Post, error) {
+	post, err := s.findStoredPost(ctx, id)
+	if err != nil {
+		return entity.Post{}, err
+	}
+
+	postResult := entity.Post{
+		ID:          post.ID,
+		AuthorID:    post.AuthorID,
+		Title:       post.Title,
+		Slug:        post.Slug,
+		Excerpt:     post.Excerpt,
+		Content:     post.Content,
+		Language:    post.Language,
+		Status:      entity.Status(post.Status),
+		IsPublished: post.IsPublished,
+		PublishedAt: post.PublishedAt,
+		CreatedDate: post.CreatedDate,
+		LastUpdated: post.LastUpdated,
+	}
+
+	return postResult, nil
+}
+
+// findStoredPost returns a post by id as the response structure.
+func (s PostServiceImpl) findStoredPost(ctx context.Context, id string) (storage.Post, error) {
+	result := <-s.PostQuery.FindByID(ctx, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrNotFound) {
+			return storage.Post{}, NewErr(ErrNotFoundCode, "post")
+		}
+		return storage.Post{}, result.Error
+	}
+
+	post, ok := result.Result.(storage.Post)
+	if !ok {
+		return storage.Post{}, errors.New("helper type assertion")
+	}
+
+	return post, nil
+}
+
+// findUserByEmail returns the user who owns the token email.
+func (s PostServiceImpl) findUserByEmail(ctx context.Context, email string) (userStorage.User, error) {
+	result := <-s.UserQuery.FindByEmail(ctx, email)
+	if result.Error != nil {
+		return userStorage.User{}, result.Error
+	}
+
+	user, ok := result.Result.(userStorage.User)
+	if !ok {
+		return userStorage.User{}, errors.New("helper type assertion")
+	}
+
+	return user, nil
+}
+
+// toStorage converts the post entity to the response structure.
+func toStorage(post *entity.Post) storage.Post {
+	return storage.Post{
+		ID:          post.ID,
+		AuthorID:    post.AuthorID,
+		Title:       post.Title,
+		Slug:        post.Slug,
+		Excerpt:     post.Excerpt,
+		Content:     post.Content,
+		Language:    post.Language,
+		Status:      string(post.Status),
+		IsPublished: post.IsPublished,
+		PublishedAt: post.PublishedAt,
+		CreatedDate: post.CreatedDate,
+		LastUpdated: post.LastUpdated,
+	}
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/slug"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/repository"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTermNameLength is the maximum length of a tag or category name.
+const maxTermNameLength = 50
+
+// taxonomy pairs the query and the command of tags or categories,
+// so that both can share the same business logic.
+type taxonomy struct {
+	query   query.TermQuery
+	command repository.TermCommand
+	field   string
+}
+
+func (s *PostServiceImpl) tags() taxonomy {
+	return taxonomy{query: s.TagQuery, command: s.TagCommand, field: "tag"}
+}
+
+func (s *PostServiceImpl) categories() taxonomy {
+	return taxonomy{query: s.CategoryQuery, command: s.CategoryCommand, field: "category"}
+}
+
+// FindTags returns every tag with its number of published posts.
+func (s *PostServiceImpl) FindTags(ctx context.Context) ([]storage.Term, error) {
+	return s.findTerms(ctx, s.tags())
+}
+
+// FindCategories returns every category with its number of published posts.
+func (s *PostServiceImpl) FindCategories(ctx context.Context) ([]storage.Term, error) {
+	return s.findTerms(ctx, s.categories())
+}
+
+// FindPostsByTag returns the published posts linked to the tag.
+func (s *PostServiceImpl) FindPostsByTag(ctx context.Context, slug string, page, limit int) (storage.PostList, error) {
+	_, err := s.findTerm(ctx, s.tags(), slug)
+	if err != nil {
+		return storage.PostList{}, err
+	}
+
+	return s.findPostList(s.PostQuery.FindPublishedByTag(ctx, slug, limit, (page-1)*limit), page, limit)
+}
+
+// FindPostsByCategory returns the published posts linked to the category.
+func (s *PostServiceImpl) FindPostsByCategory(ctx context.Context, slug string, page, limit int) (storage.PostList, error) {
+	_, err := s.findTerm(ctx, s.categories(), slug)
+	if err != nil {
+		return storage.PostList{}, err
+	}
+
+	return s.findPostList(s.PostQuery.FindPublishedByCategory(ctx, slug, limit, (page-1)*limit), page, limit)
+}
+
+// AttachTag links a tag to a post of the user, the tag is created if needed.
+func (s *PostServiceImpl) AttachTag(ctx context.Context, postID, name, email string) (storage.Term, error) {
+	return s.attachTerm(ctx, s.tags(), postID, name, email)
+}
+
+// DetachTag unlinks a tag from a post of the user.
+func (s *PostServiceImpl) DetachTag(ctx context.Context, postID, slug, email string) error {
+	return s.detachTerm(ctx, s.tags(), postID, slug, email)
+}
+
+// AttachCategory links a category to a post of the user, the category is created if needed.
+func (s *PostServiceImpl) AttachCategory(ctx context.Context, postID, name, email string) (storage.Term, error) {
+	return s.attachTerm(ctx, s.categories(), postID, name, email)
+}
+
+// DetachCategory unlinks a category from a post of the user.
+func (s *PostServiceImpl) DetachCategory(ctx context.Context, postID, slug, email string) error {
+	return s.detachTerm(ctx, s.categories(), postID, slug, email)
+}
+
+func (s *PostServiceImpl) findTerms(ctx context.Context, t taxonomy) ([]storage.Term, error) {
+	result := <-t.query.FindAll(ctx)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	terms, ok := result.Result.([]storage.Term)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	return terms, nil
+}
+
+func (s *PostServiceImpl) findTerm(ctx context.Context, t taxonomy, slug string) (storage.Term, error) {
+	result := <-t.query.FindBySlug(ctx, slug)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrTermNotFound) {
+			return storage.Term{}, NewErr(ErrNotFoundCode, t.field)
+		}
+		return storage.Term{}, result.Error
+	}
+
+	term, ok := result.Result.(storage.Term)
+	if !ok {
+		return storage.Term{}, errors.New("helper type assertion")
+	}
+
+	return term, nil
+}
+
+func (s *PostServiceImpl) findPostTerms(ctx context.Context, t taxonomy, postID string) ([]storage.Term, error) {
+	result := <-t.query.FindByPost(ctx, postID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	terms, ok := result.Result.([]storage.Term)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	return terms, nil
+}
+
+func (s *PostServiceImpl) attachTerm(ctx context.Context, t taxonomy, postID, name, email string) (storage.Term, error) {
+	if name == "" || slug.Make(name) == "" {
+		return storage.Term{}, NewErr(ErrNameEmptyCode, "name")
+	}
+	if utf8.RuneCountInString(name) > maxTermNameLength {
+		return storage.Term{}, NewErr(ErrFieldTooLongCode, "name")
+	}
+
+	post, err := s.findAuthoredPost(ctx, postID, email)
+	if err != nil {
+		return storage.Term{}, err
+	}
+
+	term, err := s.saveTerm(ctx, t, name)
+	if err != nil {
+		return storage.Term{}, err
+	}
+
+	err = <-t.command.Attach(ctx, post.ID, term.ID)
+	if err != nil {
+		return storage.Term{}, err
+	}
+
+	return term, nil
+}
+
+// saveTerm returns the term with the name, created if needed. names that
+// make the same slug, like "C++" and "C#", get their own slug with a suffix.
+// names that only differ in case are the same term.
+func (s *PostServiceImpl) saveTerm(ctx context.Context, t taxonomy, name string) (storage.Term, error) {
+	var existing *storage.Term
+	termSlug, err := slug.Unique(slug.Make(name), func(candidate string) (bool, error) {
+		result := <-t.query.FindBySlug(ctx, candidate)
+		if errors.Is(result.Error, query.ErrTermNotFound) {
+			return false, nil
+		}
+		if result.Error != nil {
+			return false, result.Error
+		}
+
+		term, ok := result.Result.(storage.Term)
+		if !ok {
+			return false, errors.New("helper type assertion")
+		}
+		if strings.EqualFold(term.Name, name) {
+			existing = &term
+			return false, nil
+		}
+		return true, nil
+	})
+	if err != nil {
+		return storage.Term{}, err
+	}
+	if existing != nil {
+		return *existing, nil
+	}
+
+	// save does nothing when the term has been created meanwhile,
+	// so read it back by slug to get the stored one.
+	newTerm := entity.CreateTerm(name)
+	newTerm.Slug = termSlug
+	err = <-t.command.Save(ctx, newTerm)
+	if err != nil {
+		return storage.Term{}, err
+	}
+
+	term, err := s.findTerm(ctx, t, termSlug)
+	if err != nil {
+		return storage.Term{}, err
+	}
+	if !strings.EqualFold(term.Name, name) {
+		return storage.Term{}, NewErr(ErrSlugExistsCode, "name")
+	}
+
+	return term, nil
+}
+
+func (s *PostServiceImpl) detachTerm(ctx context.Context, t taxonomy, postID, slug, email string) error {
+	post, err := s.findAuthoredPost(ctx, postID, email)
+	if err != nil {
+		return err
+	}
+
+	term, err := s.findTerm(ctx, t, slug)
+	if err != nil {
+		return err
+	}
+
+	err = <-t.command.Detach(ctx, post.ID, term.ID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/post/entity"
+	"github.com/SemmiDev/blog/internal/post/query"
+	"github.com/SemmiDev/blog/internal/post/storage"
+	"testing"
+)
+
+// terms is a TermQuery and TermCommand that keeps the terms by slug.
+type terms map[string]storage.Term
+
+func (m terms) FindAll(ctx context.Context) <-chan query.Result {
+	result := make(chan query.Result, 1)
+	result <- query.Result{Error: query.ErrTermNotFound}
+	return result
+}
+
+func (m terms) FindBySlug(ctx context.Context, slug string) <-chan query.Result {
+	result := make(chan query.Result, 1)
+	term, ok := m[slug]
+	if !ok {
+		result <- query.Result{Error: query.ErrTermNotFound}
+		return result
+	}
+	result <- query.Result{Result: term}
+	return result
+}
+
+func (m terms) FindByPost(ctx context.Context, postID string) <-chan query.Result {
+	return m.FindAll(ctx)
+}
+
+func (m terms) Save(ctx context.Context, arg *entity.Term) <-chan error {
+	if _, ok := m[arg.Slug]; !ok {
+		m[arg.Slug] = storage.Term{ID: arg.ID, Name: arg.Name, Slug: arg.Slug}
+	}
+	result := make(chan error, 1)
+	result <- nil
+	return result
+}
+
+func (m terms) Attach(ctx context.Context, postID, termID string) <-chan error {
+	result := make(chan error, 1)
+	result <- nil
+	return result
+}
+
+func (m terms) Detach(ctx context.Context, postID, termID string) <-chan error {
+	return m.Attach(ctx, postID, termID)
+}
+
+func TestSaveTerm(t *testing.T) {
+	store := terms{}
+	s := &PostServiceImpl{}
+	tags := taxonomy{query: store, command: store, field: "tag"}
+
+	tests := []struct {
+		name     string
+		wantSlug string
+		wantName string
+	}{
+		{name: "C++", wantSlug: "c", wantName: "C++"},
+		{name: "C#", wantSlug: "c-2", wantName: "C#"},
+		{name: "C", wantSlug: "c-3", wantName: "C"},
+		{name: "C#", wantSlug: "c-2", wantName: "C#"},
+		{name: "c++", wantSlug: "c", wantName: "C++"},
+	}
+
+	for _, tt := range tests {
+		term, err := s.saveTerm(context.Background(), tags, tt.name)
+		if err != nil {
+			t.Fatalf("saveTerm(%q) error = %v", tt.name, err)
+		}
+		if term.Slug != tt.wantSlug || term.Name != tt.wantName {
+			t.Errorf("saveTerm(%q) = %s %q, want %s %q", tt.name, term.Slug, term.Name, tt.wantSlug, tt.wantName)
+		}
+	}
+
+	if len(store) != 3 {
+		t.Errorf("stored %d terms, want 3", len(store))
+	}
+}
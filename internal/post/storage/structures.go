@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	"time"
+)
+
+// Post will be used as response for get details of post.
+type Post struct {
+	ID            string             `json:"id"`
+	AuthorID      string             `json:"author_id"`
+	Title         string             `json:"title"`
+	Slug          string             `json:"slug"`
+	Excerpt       string             `json:"excerpt"`
+	Content       string             `json:"content"`
+	ContentHTML   string             `json:"content_html,omitempty"`
+	TOC           []markdown.Heading `json:"toc,omitempty"`
+	Language      string             `json:"language"`
+	Status        string             `json:"status"`
+	IsPublished   bool               `json:"is_published"`
+	PublishedAt   time.Time          `json:"published_at"`
+	CreatedDate   time.Time          `json:"created_at"`
+	LastUpdated   time.Time          `json:"updated_at"`
+	FavoriteCount int                `json:"favorite_count"`
+	Tags          []Term             `json:"tags,omitempty"`
+	Categories    []Term             `json:"categories,omitempty"`
+}
+
+// PostList will be used as response for paginated posts.
+type PostList struct {
+	Posts []Post `json:"posts"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+	Total int    `json:"total"`
+}
+
+// Term will be used as response for tags and categories.
+type Term struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int    `json:"post_count"`
+}
+
+// SearchResult will be used as response for a post matching a search.
+// title and snippet are html with the matching words wrapped in <mark>.
+type SearchResult struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Snippet     string    `json:"snippet"`
+	Rank        float32   `json:"rank"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// SearchList will be used as response for paginated search results.
+type SearchList struct {
+	Query    string         `json:"query"`
+	Language string         `json:"language"`
+	Results  []SearchResult `json:"results"`
+	Page     int            `json:"page"`
+	Limit    int            `json:"limit"`
+	Total    int            `json:"total"`
+}
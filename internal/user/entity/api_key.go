@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// APIKey represents an api_keys table in the database.
+// an api key authenticates the scripts of a user, only its hash is stored.
+type APIKey struct {
+	ID          string
+	UserID      string
+	Name        string
+	Prefix      string
+	KeyHash     []byte
+	Scopes      []string
+	ExpiredAt   *time.Time
+	CreatedDate time.Time
+}
+
+// CreateAPIKey creates a new api key of the user and returns it.
+// a nil expiredAt never expires.
+func CreateAPIKey(userID, name, prefix string, keyHash []byte, scopes []string, expiredAt *time.Time) *APIKey {
+	return &APIKey{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		Name:        name,
+		Prefix:      prefix,
+		KeyHash:     keyHash,
+		Scopes:      scopes,
+		ExpiredAt:   expiredAt,
+		CreatedDate: time.Now(),
+	}
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// AuditAction is what an audit event records.
+type AuditAction string
+
+// the actions of the admins on the other users.
+const (
+	AuditUserSuspended       AuditAction = "user.suspended"
+	AuditUserUnsuspended     AuditAction = "user.unsuspended"
+	AuditPasswordResetForced AuditAction = "user.password_reset_forced"
+	AuditRoleChanged         AuditAction = "user.role_changed"
+	AuditUserDeleted         AuditAction = "user.deleted"
+)
+
+// the security events of the users on their own account.
+const (
+	AuditUserRegistered  AuditAction = "user.registered"
+	AuditLoginSucceeded  AuditAction = "auth.login"
+	AuditLoginFailed     AuditAction = "auth.login_failed"
+	AuditAccountLocked   AuditAction = "auth.account_locked"
+	AuditAccountUnlocked AuditAction = "auth.account_unlocked"
+	AuditPasswordChanged AuditAction = "user.password_changed"
+	AuditPasswordReset   AuditAction = "user.password_reset"
+	AuditProfileUpdated  AuditAction = "user.profile_updated"
+)
+
+// the requests of the users about their data.
+const (
+	AuditDataExported      AuditAction = "user.data_exported"
+	AuditDeletionScheduled AuditAction = "user.deletion_scheduled"
+	AuditDeletionCancelled AuditAction = "user.deletion_cancelled"
+)
+
+// AuditEvent represents an audit_events table in the database.
+// the actor is the user who acted, the target the user it has been done to,
+// either is empty when there is none, the metadata holds the details.
+type AuditEvent struct {
+	ID          string
+	ActorID     string
+	Action      AuditAction
+	TargetID    string
+	IP          string
+	UserAgent   string
+	Metadata    map[string]string
+	CreatedDate time.Time
+}
+
+// CreateAuditEvent creates a new audit event and returns it.
+func CreateAuditEvent(actorID string, action AuditAction, targetID, ip, userAgent string, metadata map[string]string) *AuditEvent {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+
+	return &AuditEvent{
+		ID:          uuid.NewString(),
+		ActorID:     actorID,
+		Action:      action,
+		TargetID:    targetID,
+		IP:          ip,
+		UserAgent:   TruncateUserAgent(userAgent),
+		Metadata:    metadata,
+		CreatedDate: time.Now(),
+	}
+}
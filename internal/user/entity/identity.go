@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+// Identity represents an identities table in the database.
+// an identity links the subject of an OpenID Connect provider to a user.
+type Identity struct {
+	Provider    string
+	Subject     string
+	UserID      string
+	CreatedDate time.Time
+}
+
+// CreateIdentity creates a new identity of the user and returns it.
+func CreateIdentity(provider, subject, userID string) *Identity {
+	return &Identity{
+		Provider:    provider,
+		Subject:     subject,
+		UserID:      userID,
+		CreatedDate: time.Now(),
+	}
+}
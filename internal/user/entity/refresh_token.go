@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+// RefreshToken represents a refresh_tokens table in the database.
+// the tokens rotated from the same login share a family.
+// the access token issued along with it is kept so that it can be revoked too.
+type RefreshToken struct {
+	ID              string
+	FamilyID        string
+	UserID          string
+	AccessTokenID   string
+	AccessExpiredAt time.Time
+	ExpiredAt       time.Time
+	CreatedDate     time.Time
+}
+
+// CreateRefreshToken creates a refresh token of the payload id.
+// an empty familyID starts a new family.
+func CreateRefreshToken(id, familyID, userID string, expiredAt time.Time) *RefreshToken {
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+
+	return &RefreshToken{
+		ID:          id,
+		FamilyID:    familyID,
+		UserID:      userID,
+		ExpiredAt:   expiredAt,
+		CreatedDate: time.Now(),
+	}
+}
+
+// IssueWith records the access token issued along with the refresh token.
+func (r *RefreshToken) IssueWith(accessTokenID string, accessExpiredAt time.Time) {
+	r.AccessTokenID = accessTokenID
+	r.AccessExpiredAt = accessExpiredAt
+}
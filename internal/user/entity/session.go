@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"github.com/google/uuid"
+	"time"
+	"unicode/utf8"
+)
+
+// maxUserAgentLength is the maximum length of the user_agent column.
+const maxUserAgentLength = 255
+
+// Session represents a sessions table in the database.
+// a session is a login on a device, its id is the family of its refresh tokens.
+type Session struct {
+	ID          string
+	UserID      string
+	UserAgent   string
+	IP          string
+	CreatedDate time.Time
+	LastSeenAt  time.Time
+	ExpiredAt   time.Time
+}
+
+// CreateSession creates a new session of the user and returns it.
+func CreateSession(userID, userAgent, ip string, expiredAt time.Time) *Session {
+	now := time.Now()
+	return &Session{
+		ID:          uuid.NewString(),
+		UserID:      userID,
+		UserAgent:   TruncateUserAgent(userAgent),
+		IP:          ip,
+		CreatedDate: now,
+		LastSeenAt:  now,
+		ExpiredAt:   expiredAt,
+	}
+}
+
+// TruncateUserAgent cuts the user agent to the length of the user_agent column.
+func TruncateUserAgent(userAgent string) string {
+	if utf8.RuneCountInString(userAgent) <= maxUserAgentLength {
+		return userAgent
+	}
+	return string([]rune(userAgent)[:maxUserAgentLength])
+}
+
+// Refresh records that the session has been refreshed from the device,
+// it now expires along with its new refresh token.
+func (s *Session) Refresh(userAgent, ip string, expiredAt time.Time) {
+	s.UserAgent = TruncateUserAgent(userAgent)
+	s.IP = ip
+	s.LastSeenAt = time.Now()
+	s.ExpiredAt = expiredAt
+}
@@ -1,6 +1,7 @@
 package entity
 
 import (
+	"github.com/SemmiDev/blog/internal/user/rbac"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"strings"
@@ -17,6 +18,19 @@ type User struct {
 	Bio         string
 	Image       string
 	CreatedDate time.Time
+	// MFASecret is the encrypted totp secret, MFAEnabledAt is set once
+	// the secret has been confirmed with a code.
+	MFASecret    []byte
+	MFAEnabledAt *time.Time
+	MFALastStep  int64
+	Role         rbac.Role
+	// SuspendedAt is set while an admin has suspended the user,
+	// PasswordResetRequired while an admin has forced a password reset.
+	SuspendedAt           *time.Time
+	PasswordResetRequired bool
+	// DeletionScheduledAt is when the account is deleted,
+	// it is set while the user can still cancel the deletion.
+	DeletionScheduledAt *time.Time
 }
 
 // CreateUser creates a new user and returns it.
@@ -33,6 +47,7 @@ func CreateUser(email, name, password string) (*User, error) {
 		Nickname: nickname,
 		Email:    email,
 		Password: hash,
+		Role:     rbac.DefaultRole,
 	}
 
 	return &user, nil
@@ -54,7 +69,7 @@ func (u *User) ChangePassword(oldPassword, newPassword, newConfirmPassword strin
 	return nil
 }
 
-// ResetPassword resets the user's password.
+// ResetPassword resets the user's password, it fulfills a forced password reset.
 func (u *User) ResetPassword(newPassword string) error {
 	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -62,5 +77,71 @@ func (u *User) ResetPassword(newPassword string) error {
 	}
 
 	u.Password = hash
+	u.PasswordResetRequired = false
 	return nil
 }
+
+// RequirePasswordReset prevents the user from logging in until they reset their password.
+func (u *User) RequirePasswordReset() {
+	u.PasswordResetRequired = true
+}
+
+// EnrollMFA sets the encrypted totp secret, two-factor authentication
+// is enabled once it has been confirmed with ConfirmMFA.
+func (u *User) EnrollMFA(secret []byte) {
+	u.MFASecret = secret
+	u.MFAEnabledAt = nil
+	u.MFALastStep = 0
+}
+
+// ConfirmMFA enables two-factor authentication.
+func (u *User) ConfirmMFA(at time.Time) {
+	u.MFAEnabledAt = &at
+}
+
+// DisableMFA removes the totp secret.
+func (u *User) DisableMFA() {
+	u.MFASecret = nil
+	u.MFAEnabledAt = nil
+	u.MFALastStep = 0
+}
+
+// IsMFAEnabled tells whether the user logs in with a totp code.
+func (u *User) IsMFAEnabled() bool {
+	return u.MFAEnabledAt != nil
+}
+
+// Suspend prevents the user from logging in until they are unsuspended.
+func (u *User) Suspend(at time.Time) {
+	u.SuspendedAt = &at
+}
+
+// Unsuspend allows the user to log in again.
+func (u *User) Unsuspend() {
+	u.SuspendedAt = nil
+}
+
+// IsSuspended tells whether the user has been suspended.
+func (u *User) IsSuspended() bool {
+	return u.SuspendedAt != nil
+}
+
+// ChangeRole changes the role of the user.
+func (u *User) ChangeRole(role rbac.Role) {
+	u.Role = role
+}
+
+// ScheduleDeletion deletes the account at the time, unless the deletion is cancelled before.
+func (u *User) ScheduleDeletion(at time.Time) {
+	u.DeletionScheduledAt = &at
+}
+
+// CancelDeletion keeps the account.
+func (u *User) CancelDeletion() {
+	u.DeletionScheduledAt = nil
+}
+
+// IsDeletionScheduled tells whether the account is going to be deleted.
+func (u *User) IsDeletionScheduled() bool {
+	return u.DeletionScheduledAt != nil
+}
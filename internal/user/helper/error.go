@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"github.com/SemmiDev/blog/internal/common/logger"
 	"github.com/gofiber/fiber/v2"
+	"math"
 	"net/http"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -28,6 +30,33 @@ const (
 	ErrAuthorizationTypeBearerCode
 	ErrAuthorizationPayloadKeyCode
 	ErrAuthorizationInvalidTokenCode
+
+	ErrTitleEmptyCode
+	ErrContentEmptyCode
+	ErrFieldTooLongCode
+	ErrSlugExistsCode
+	ErrNotFoundCode
+	ErrForbiddenCode
+	ErrInvalidStatusTransitionCode
+	ErrScheduleInPastCode
+	ErrUnsupportedLanguageCode
+	ErrSearchQueryEmptyCode
+	ErrRefreshTokenReusedCode
+	ErrTooManyRequestsCode
+	ErrCodeAttemptsExceededCode
+	ErrAccountLockedCode
+	ErrLoginDelayedCode
+	ErrMFAAlreadyEnabledCode
+	ErrMFANotEnabledCode
+	ErrEmailNotVerifiedCode
+	ErrOIDCDeniedCode
+	ErrInvalidScopeCode
+	ErrExpiryInPastCode
+	ErrInsufficientScopeCode
+	ErrAccountSuspendedCode
+	ErrPasswordResetRequiredCode
+	ErrInvalidRoleCode
+	ErrMarkupNotAllowedCode
 )
 
 type Err struct {
@@ -53,6 +82,20 @@ func (r Err) Error() string {
 	)
 }
 
+// RetryErr is an Err of a request that can be made again after RetryAfter,
+// it is answered with a Retry-After header.
+type RetryErr struct {
+	Err
+	RetryAfter time.Duration
+}
+
+func NewRetryErr(errorCode int, fieldName string, retryAfter time.Duration) RetryErr {
+	return RetryErr{
+		Err:        NewErr(errorCode, fieldName),
+		RetryAfter: retryAfter,
+	}
+}
+
 func Message(errorCode int) string {
 	switch errorCode {
 	case ErrEmailEmptyCode:
@@ -87,11 +130,79 @@ func Message(errorCode int) string {
 		return "code is invalid"
 	case ErrParseCode:
 		return "parse error"
+	case ErrTitleEmptyCode:
+		return "Title is empty"
+	case ErrContentEmptyCode:
+		return "Content is empty"
+	case ErrFieldTooLongCode:
+		return "Field is too long"
+	case ErrSlugExistsCode:
+		return "Slug exists"
+	case ErrNotFoundCode:
+		return "Resource not found"
+	case ErrForbiddenCode:
+		return "You are not allowed to do this action"
+	case ErrInvalidStatusTransitionCode:
+		return "Status can not be changed to the requested state"
+	case ErrScheduleInPastCode:
+		return "Schedule time must be in the future"
+	case ErrUnsupportedLanguageCode:
+		return "Language is not supported"
+	case ErrSearchQueryEmptyCode:
+		return "Search query is empty"
+	case ErrRefreshTokenReusedCode:
+		return "Refresh token has already been used, its login has been revoked"
+	case ErrTooManyRequestsCode:
+		return "Too many requests, try again later"
+	case ErrCodeAttemptsExceededCode:
+		return "Too many wrong codes, request a new one"
+	case ErrAccountLockedCode:
+		return "Account is locked after too many failed logins, unlock it with the code sent to your email"
+	case ErrLoginDelayedCode:
+		return "Too many failed logins, wait before trying again"
+	case ErrMFAAlreadyEnabledCode:
+		return "Two-factor authentication is already enabled"
+	case ErrMFANotEnabledCode:
+		return "Two-factor authentication has not been set up"
+	case ErrEmailNotVerifiedCode:
+		return "Email has not been verified by the provider"
+	case ErrOIDCDeniedCode:
+		return "Sign in has been denied by the provider"
+	case ErrInvalidScopeCode:
+		return "Scope is invalid"
+	case ErrExpiryInPastCode:
+		return "Expiry time must be in the future"
+	case ErrInsufficientScopeCode:
+		return "API key is not allowed to do this action"
+	case ErrAccountSuspendedCode:
+		return "Account has been suspended"
+	case ErrPasswordResetRequiredCode:
+		return "Password has to be reset, use the code sent to your email or ask for a new one"
+	case ErrInvalidRoleCode:
+		return "Role is invalid"
+	case ErrMarkupNotAllowedCode:
+		return "Html is not allowed, only plain text"
 	default:
 		return "Internal server error"
 	}
 }
 
+// Status returns the http status code for the error code.
+func Status(errorCode int) int {
+	switch errorCode {
+	case ErrNotFoundCode:
+		return http.StatusNotFound
+	case ErrForbiddenCode, ErrInsufficientScopeCode, ErrAccountSuspendedCode, ErrPasswordResetRequiredCode:
+		return http.StatusForbidden
+	case ErrTooManyRequestsCode, ErrLoginDelayedCode:
+		return http.StatusTooManyRequests
+	case ErrAccountLockedCode:
+		return http.StatusLocked
+	default:
+		return http.StatusBadRequest
+	}
+}
+
 func Error(c *fiber.Ctx, err error) error {
 	errorResponse := map[string]string{
 		"field_name":    "",
@@ -101,6 +212,11 @@ func Error(c *fiber.Ctx, err error) error {
 
 	file, line := getFileAndLineNumber()
 
+	if retryErr, ok := err.(RetryErr); ok {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryErr.RetryAfter.Seconds()))))
+		err = retryErr.Err
+	}
+
 	theError, ok := err.(Err)
 	if ok {
 		errorResponse["error_code"] = theError.ErrorCode
@@ -113,7 +229,8 @@ func Error(c *fiber.Ctx, err error) error {
 			"ERROR": errorResponse,
 		}
 		logger.Log.Error().Interface("err", fields).Send()
-		return c.Status(http.StatusBadRequest).JSON(errorResponse)
+		code, _ := strconv.Atoi(theError.ErrorCode)
+		return c.Status(Status(code)).JSON(errorResponse)
 	}
 
 	logger.Log.Error().Interface("err", err.Error()).Send()
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/SemmiDev/blog/config"
 	"github.com/SemmiDev/blog/internal/common/logger"
+	"google.golang.org/api/iterator"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -78,6 +79,39 @@ func UploadImage(ctx context.Context, cloudStorage *cloud.Client, file *multipar
 	return result
 }
 
+// DeleteImages deletes every image uploaded for the email from the cloud storage,
+// the previous profile images are kept by UploadImage, so they are all deleted.
+func DeleteImages(ctx context.Context, cloudStorage *cloud.Client, email string) chan error {
+	result := make(chan error)
+	go func() {
+		defer close(result)
+
+		bucket := cloudStorage.Bucket(config.Env.FirebaseBucketName)
+		objects := bucket.Objects(ctx, &cloud.Query{Prefix: email + "_profile_"})
+		for {
+			attrs, err := objects.Next()
+			if errors.Is(err, iterator.Done) {
+				break
+			}
+			if err != nil {
+				result <- err
+				return
+			}
+
+			err = bucket.Object(attrs.Name).Delete(ctx)
+			if err != nil && !errors.Is(err, cloud.ErrObjectNotExist) {
+				result <- err
+				return
+			}
+		}
+
+		logger.Log.Info().Msg("images deleted")
+		result <- nil
+	}()
+
+	return result
+}
+
 // DefineFileName generates a unique file name for the image.
 func DefineFileName(kind string, email string, ext string) string {
 	var filename bytes.Buffer
@@ -1,16 +1,15 @@
 package memory
 
 import (
-	"errors"
 	"github.com/SemmiDev/blog/internal/common/memory"
 	"github.com/SemmiDev/blog/internal/user/query"
 )
 
 type TokenQueryMemory struct {
-	DB *memory.Storage
+	DB memory.Store
 }
 
-func NewTokenQueryMemory(DB *memory.Storage) *TokenQueryMemory {
+func NewTokenQueryMemory(DB memory.Store) *TokenQueryMemory {
 	return &TokenQueryMemory{DB: DB}
 }
 
@@ -18,13 +17,19 @@ func (s *TokenQueryMemory) Find(key string) <-chan query.Result {
 	result := make(chan query.Result)
 
 	go func() {
-		data, _ := s.DB.Get(key)
+		defer close(result)
+
+		data, err := s.DB.Get(key)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
 		if data == nil {
-			result <- query.Result{Error: errors.New("data not found")}
+			result <- query.Result{Error: query.ErrTokenNotFound}
+			return
 		}
 
 		result <- query.Result{Result: data}
-		close(result)
 	}()
 
 	return result
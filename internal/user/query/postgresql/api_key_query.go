@@ -0,0 +1,112 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const apiKeyColumns = `api_keys.id, api_keys.user_id, users.email, users.role, api_keys.name, api_keys.prefix,
+	api_keys.scopes, api_keys.expired_at, api_keys.last_used_at, api_keys.created_at`
+
+type APIKeyQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewAPIKeyQueryPostgresql(DB *pgxpool.Pool) *APIKeyQueryPostgresql {
+	return &APIKeyQueryPostgresql{DB: DB}
+}
+
+func (q APIKeyQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	return q.findOne(ctx, `api_keys.id = $1`, id)
+}
+
+// FindByHash returns the api key with the hash, the keys of suspended users are not found.
+func (q APIKeyQueryPostgresql) FindByHash(ctx context.Context, hash []byte) <-chan query.Result {
+	return q.findOne(ctx, `api_keys.key_hash = $1 AND users.suspended_at IS NULL`, hash)
+}
+
+func (q APIKeyQueryPostgresql) FindByUser(ctx context.Context, userID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT `+apiKeyColumns+`
+			FROM api_keys
+				JOIN users ON users.id = api_keys.user_id
+			WHERE api_keys.user_id = $1
+			ORDER BY api_keys.created_at DESC`, userID)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		apiKeys := []storage.APIKey{}
+		for rows.Next() {
+			apiKey, err := scanAPIKey(rows)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			apiKeys = append(apiKeys, apiKey)
+		}
+		if rows.Err() != nil {
+			result <- query.Result{Error: rows.Err()}
+			return
+		}
+
+		result <- query.Result{Result: apiKeys}
+	}()
+
+	return result
+}
+
+func (q APIKeyQueryPostgresql) findOne(ctx context.Context, where string, arg interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		apiKey, err := scanAPIKey(q.DB.QueryRow(ctx, `SELECT `+apiKeyColumns+`
+			FROM api_keys
+				JOIN users ON users.id = api_keys.user_id
+			WHERE `+where, arg))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrAPIKeyNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: apiKey}
+	}()
+
+	return result
+}
+
+func scanAPIKey(row pgx.Row) (storage.APIKey, error) {
+	apiKey := storage.APIKey{}
+	role := ""
+	err := row.Scan(
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.Email,
+		&role,
+		&apiKey.Name,
+		&apiKey.Prefix,
+		&apiKey.Scopes,
+		&apiKey.ExpiredAt,
+		&apiKey.LastUsedAt,
+		&apiKey.CreatedDate,
+	)
+	apiKey.Role = rbac.Role(role)
+	return apiKey, err
+}
@@ -0,0 +1,72 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuditEventQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewAuditEventQueryPostgresql(DB *pgxpool.Pool) *AuditEventQueryPostgresql {
+	return &AuditEventQueryPostgresql{DB: DB}
+}
+
+func (q AuditEventQueryPostgresql) FindAll(ctx context.Context, userID, action string, limit, offset int) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT audit_events.id, COALESCE(audit_events.actor_id, ''),
+				COALESCE(actors.email, ''), audit_events.action, COALESCE(audit_events.target_id, ''),
+				COALESCE(targets.email, ''), audit_events.ip, audit_events.user_agent,
+				audit_events.metadata, audit_events.created_at, COUNT(*) OVER()
+			FROM audit_events
+				LEFT JOIN users actors ON actors.id = audit_events.actor_id
+				LEFT JOIN users targets ON targets.id = audit_events.target_id
+			WHERE ($3 = '' OR audit_events.actor_id = $3 OR audit_events.target_id = $3)
+			AND ($4 = '' OR audit_events.action = $4)
+			ORDER BY audit_events.created_at DESC
+			LIMIT $1 OFFSET $2`, limit, offset, userID, action)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		list := storage.AuditEventList{Events: []storage.AuditEvent{}}
+		for rows.Next() {
+			event := storage.AuditEvent{}
+			err := rows.Scan(
+				&event.ID,
+				&event.ActorID,
+				&event.ActorEmail,
+				&event.Action,
+				&event.TargetID,
+				&event.TargetEmail,
+				&event.IP,
+				&event.UserAgent,
+				&event.Metadata,
+				&event.CreatedDate,
+				&list.Total,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			list.Events = append(list.Events, event)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: list}
+	}()
+
+	return result
+}
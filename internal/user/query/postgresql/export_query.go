@@ -0,0 +1,167 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ExportQueryPostgresql reads the tables of the post and comment modules directly,
+// so that the user module does not depend on them.
+type ExportQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewExportQueryPostgresql(DB *pgxpool.Pool) *ExportQueryPostgresql {
+	return &ExportQueryPostgresql{DB: DB}
+}
+
+func (q ExportQueryPostgresql) FindPosts(ctx context.Context, userID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT posts.id, posts.title, posts.slug, posts.excerpt,
+				posts.content, posts.language::text, posts.status,
+				ARRAY(SELECT tags.name FROM posts_tags JOIN tags ON tags.id = posts_tags.tag_id
+					WHERE posts_tags.post_id = posts.id ORDER BY tags.name),
+				ARRAY(SELECT categories.name FROM posts_categories
+					JOIN categories ON categories.id = posts_categories.category_id
+					WHERE posts_categories.post_id = posts.id ORDER BY categories.name),
+				posts.published_at, posts.created_at, posts.updated_at
+			FROM posts
+			WHERE posts.author_id = $1
+			ORDER BY posts.created_at`, userID)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		posts := []storage.ExportedPost{}
+		for rows.Next() {
+			post := storage.ExportedPost{}
+			err := rows.Scan(
+				&post.ID,
+				&post.Title,
+				&post.Slug,
+				&post.Excerpt,
+				&post.Content,
+				&post.Language,
+				&post.Status,
+				&post.Tags,
+				&post.Categories,
+				&post.PublishedAt,
+				&post.CreatedDate,
+				&post.LastUpdated,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			posts = append(posts, post)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: posts}
+	}()
+
+	return result
+}
+
+func (q ExportQueryPostgresql) FindComments(ctx context.Context, userID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT comments.id, comments.post_id, posts.slug,
+				COALESCE(comments.parent_id, ''), comments.content, comments.status,
+				comments.created_at, comments.updated_at
+			FROM comments
+				JOIN posts ON posts.id = comments.post_id
+			WHERE comments.user_id = $1
+			ORDER BY comments.created_at`, userID)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		comments := []storage.ExportedComment{}
+		for rows.Next() {
+			comment := storage.ExportedComment{}
+			err := rows.Scan(
+				&comment.ID,
+				&comment.PostID,
+				&comment.PostSlug,
+				&comment.ParentID,
+				&comment.Content,
+				&comment.Status,
+				&comment.CreatedDate,
+				&comment.LastUpdated,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			comments = append(comments, comment)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: comments}
+	}()
+
+	return result
+}
+
+func (q ExportQueryPostgresql) FindFavorites(ctx context.Context, userID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT favorites.post_id, posts.slug, posts.title, favorites.created_at
+			FROM favorites
+				JOIN posts ON posts.id = favorites.post_id
+			WHERE favorites.user_id = $1
+			ORDER BY favorites.created_at`, userID)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		favorites := []storage.ExportedFavorite{}
+		for rows.Next() {
+			favorite := storage.ExportedFavorite{}
+			err := rows.Scan(
+				&favorite.PostID,
+				&favorite.PostSlug,
+				&favorite.PostTitle,
+				&favorite.CreatedDate,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			favorites = append(favorites, favorite)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: favorites}
+	}()
+
+	return result
+}
@@ -0,0 +1,51 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type IdentityQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewIdentityQueryPostgresql(DB *pgxpool.Pool) *IdentityQueryPostgresql {
+	return &IdentityQueryPostgresql{DB: DB}
+}
+
+func (q IdentityQueryPostgresql) FindBySubject(ctx context.Context, provider, subject string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		identity := storage.Identity{}
+		err := q.DB.QueryRow(ctx, `SELECT identities.provider, identities.subject, identities.user_id,
+				users.email, identities.created_at
+			FROM identities
+				JOIN users ON users.id = identities.user_id
+			WHERE identities.provider = $1 AND identities.subject = $2`, provider, subject).Scan(
+			&identity.Provider,
+			&identity.Subject,
+			&identity.UserID,
+			&identity.Email,
+			&identity.CreatedDate,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrIdentityNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: identity}
+	}()
+
+	return result
+}
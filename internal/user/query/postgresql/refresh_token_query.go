@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const refreshTokenColumns = `id, family_id, user_id, COALESCE(access_token_id, ''),
+	expired_at, used_at, revoked_at, created_at`
+
+type RefreshTokenQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewRefreshTokenQueryPostgresql(DB *pgxpool.Pool) *RefreshTokenQueryPostgresql {
+	return &RefreshTokenQueryPostgresql{DB: DB}
+}
+
+func (r RefreshTokenQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	return r.findOne(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE id = $1`, id)
+}
+
+func (r RefreshTokenQueryPostgresql) FindByAccessTokenID(ctx context.Context, accessTokenID string) <-chan query.Result {
+	return r.findOne(ctx, `SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE access_token_id = $1`, accessTokenID)
+}
+
+func (r RefreshTokenQueryPostgresql) findOne(ctx context.Context, sql string, args ...interface{}) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		refreshToken := storage.RefreshToken{}
+		err := r.DB.QueryRow(ctx, sql, args...).Scan(
+			&refreshToken.ID,
+			&refreshToken.FamilyID,
+			&refreshToken.UserID,
+			&refreshToken.AccessTokenID,
+			&refreshToken.ExpiredAt,
+			&refreshToken.UsedAt,
+			&refreshToken.RevokedAt,
+			&refreshToken.CreatedDate,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrRefreshTokenNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: refreshToken}
+	}()
+
+	return result
+}
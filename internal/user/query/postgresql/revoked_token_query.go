@@ -0,0 +1,36 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type RevokedTokenQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewRevokedTokenQueryPostgresql(DB *pgxpool.Pool) *RevokedTokenQueryPostgresql {
+	return &RevokedTokenQueryPostgresql{DB: DB}
+}
+
+func (r RevokedTokenQueryPostgresql) IsRevoked(ctx context.Context, id string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		revoked := false
+		err := r.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE id = $1 AND expired_at > $2)`,
+			id, time.Now()).Scan(&revoked)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: revoked}
+	}()
+
+	return result
+}
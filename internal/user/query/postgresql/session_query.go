@@ -0,0 +1,97 @@
+package postgresql
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+const sessionColumns = `id, user_id, user_agent, ip, created_at, last_seen_at, expired_at`
+
+type SessionQueryPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewSessionQueryPostgresql(DB *pgxpool.Pool) *SessionQueryPostgresql {
+	return &SessionQueryPostgresql{DB: DB}
+}
+
+func (q SessionQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		session := storage.Session{}
+		err := q.DB.QueryRow(ctx, `SELECT `+sessionColumns+` FROM sessions
+			WHERE id = $1 AND revoked_at IS NULL`, id).Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedDate,
+			&session.LastSeenAt,
+			&session.ExpiredAt,
+		)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrSessionNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: session}
+	}()
+
+	return result
+}
+
+func (q SessionQueryPostgresql) FindActiveByUser(ctx context.Context, userID string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := q.DB.Query(ctx, `SELECT `+sessionColumns+` FROM sessions
+			WHERE user_id = $1 AND revoked_at IS NULL AND expired_at > $2
+			ORDER BY last_seen_at DESC`, userID, time.Now())
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		sessions := []storage.Session{}
+		for rows.Next() {
+			session := storage.Session{}
+			err := rows.Scan(
+				&session.ID,
+				&session.UserID,
+				&session.UserAgent,
+				&session.IP,
+				&session.CreatedDate,
+				&session.LastSeenAt,
+				&session.ExpiredAt,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			sessions = append(sessions, session)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: sessions}
+	}()
+
+	return result
+}
@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/rbac"
 	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"golang.org/x/crypto/bcrypt"
 	"strings"
@@ -19,56 +21,32 @@ func NewUserQueryPostgresql(DB *pgxpool.Pool) *UserQueryPostgresql {
 	return &UserQueryPostgresql{DB: DB}
 }
 
-type userReadResult struct {
-	ID          string
-	Name        string
-	Nickname    string
-	Email       string
-	Password    []byte
-	Bio         string
-	Image       string
-	CreatedDate time.Time
-}
+const userColumns = `id, name, nickname, email, password, bio, image, created_at,
+	mfa_secret, mfa_enabled_at, mfa_last_step, role, suspended_at, password_reset_required, deletion_scheduled_at`
+
+const accountColumns = `id, name, nickname, email, bio, image, role,
+	mfa_enabled_at IS NOT NULL, suspended_at, password_reset_required, deletion_scheduled_at, created_at`
+
+// likeEscaper escapes the wildcards of LIKE, so that a search matches them literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
 
 func (u UserQueryPostgresql) FindByEmail(ctx context.Context, email string) <-chan query.Result {
 	result := make(chan query.Result)
 
 	go func() {
-		userRead := storage.User{}
-		rowsData := userReadResult{}
-
-		err := u.DB.QueryRow(ctx, "SELECT * FROM users WHERE email = $1", email).Scan(
-			&rowsData.ID,
-			&rowsData.Name,
-			&rowsData.Nickname,
-			&rowsData.Email,
-			&rowsData.Password,
-			&rowsData.Bio,
-			&rowsData.Image,
-			&rowsData.CreatedDate,
-		)
+		defer close(result)
 
+		user, err := scanUser(u.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE email = $1`, email))
 		if err != nil {
-			exists := strings.Contains(err.Error(), "no")
-			if exists {
-				result <- query.Result{Error: errors.New("account not found")}
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrAccountNotFound}
+				return
 			}
 			result <- query.Result{Error: errors.New("internal server helper")}
+			return
 		}
 
-		userRead = storage.User{
-			ID:          rowsData.ID,
-			Name:        rowsData.Name,
-			Nickname:    rowsData.Nickname,
-			Email:       rowsData.Email,
-			Password:    rowsData.Password,
-			Bio:         rowsData.Bio,
-			Image:       rowsData.Image,
-			CreatedDate: rowsData.CreatedDate,
-		}
-
-		result <- query.Result{Result: userRead}
-		close(result)
+		result <- query.Result{Result: user}
 	}()
 
 	return result
@@ -78,48 +56,182 @@ func (u UserQueryPostgresql) FindByEmailAndPassword(ctx context.Context, email,
 	result := make(chan query.Result)
 
 	go func() {
-		userRead := storage.User{}
-		rowsData := userReadResult{}
-
-		err := u.DB.QueryRow(ctx, `SELECT * FROM users
-			WHERE email = $1`, email).Scan(
-			&rowsData.ID,
-			&rowsData.Name,
-			&rowsData.Nickname,
-			&rowsData.Email,
-			&rowsData.Password,
-			&rowsData.Bio,
-			&rowsData.Image,
-			&rowsData.CreatedDate,
-		)
+		defer close(result)
 
+		user, err := scanUser(u.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users
+			WHERE email = $1`, email))
 		if err != nil {
-			exists := strings.Contains(err.Error(), "no")
-			if exists {
-				result <- query.Result{Error: errors.New("account not found")}
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrAccountNotFound}
+				return
 			}
 			result <- query.Result{Error: errors.New("internal server helper")}
+			return
+		}
+
+		err = bcrypt.CompareHashAndPassword(user.Password, []byte(password))
+		if err != nil {
+			result <- query.Result{Error: query.ErrIncorrectPassword}
+			return
 		}
 
-		err = bcrypt.CompareHashAndPassword(rowsData.Password, []byte(password))
+		result <- query.Result{Result: user}
+	}()
+
+	return result
+}
+
+func (u UserQueryPostgresql) FindByID(ctx context.Context, id string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		user, err := scanUser(u.DB.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
 		if err != nil {
-			result <- query.Result{Error: errors.New("incorrect password")}
+			if errors.Is(err, pgx.ErrNoRows) {
+				result <- query.Result{Error: query.ErrAccountNotFound}
+				return
+			}
+			result <- query.Result{Error: err}
+			return
 		}
 
-		userRead = storage.User{
-			ID:          rowsData.ID,
-			Name:        rowsData.Name,
-			Nickname:    rowsData.Nickname,
-			Email:       rowsData.Email,
-			Password:    rowsData.Password,
-			Bio:         rowsData.Bio,
-			Image:       rowsData.Image,
-			CreatedDate: rowsData.CreatedDate,
+		result <- query.Result{Result: user}
+	}()
+
+	return result
+}
+
+func (u UserQueryPostgresql) NicknameExists(ctx context.Context, nickname string) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		exists := false
+		err := u.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE nickname = $1)`, nickname).Scan(&exists)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
 		}
 
-		result <- query.Result{Result: userRead}
-		close(result)
+		result <- query.Result{Result: exists}
 	}()
 
 	return result
 }
+
+func (u UserQueryPostgresql) FindAll(ctx context.Context, search, role string, limit, offset int) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := u.DB.Query(ctx, `SELECT `+accountColumns+`, COUNT(*) OVER() FROM users
+			WHERE ($3 = '' OR email ILIKE '%' || $3 || '%' OR name ILIKE '%' || $3 || '%'
+				OR nickname ILIKE '%' || $3 || '%')
+			AND ($4 = '' OR role = $4)
+			ORDER BY created_at DESC
+			LIMIT $1 OFFSET $2`, limit, offset, likeEscaper.Replace(search), role)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		list := storage.AccountList{Accounts: []storage.Account{}}
+		for rows.Next() {
+			account := storage.Account{}
+			accountRole := ""
+			err := rows.Scan(
+				&account.ID,
+				&account.Name,
+				&account.Nickname,
+				&account.Email,
+				&account.Bio,
+				&account.Image,
+				&accountRole,
+				&account.MFAEnabled,
+				&account.SuspendedAt,
+				&account.PasswordResetRequired,
+				&account.DeletionScheduledAt,
+				&account.CreatedDate,
+				&list.Total,
+			)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			account.Role = rbac.Role(accountRole)
+			list.Accounts = append(list.Accounts, account)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: list}
+	}()
+
+	return result
+}
+
+func (u UserQueryPostgresql) FindDueForDeletion(ctx context.Context, at time.Time) <-chan query.Result {
+	result := make(chan query.Result)
+
+	go func() {
+		defer close(result)
+
+		rows, err := u.DB.Query(ctx, `SELECT `+userColumns+` FROM users
+			WHERE deletion_scheduled_at <= $1
+			ORDER BY deletion_scheduled_at`, at)
+		if err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+		defer rows.Close()
+
+		users := []storage.User{}
+		for rows.Next() {
+			user, err := scanUser(rows)
+			if err != nil {
+				result <- query.Result{Error: err}
+				return
+			}
+			users = append(users, user)
+		}
+		if err := rows.Err(); err != nil {
+			result <- query.Result{Error: err}
+			return
+		}
+
+		result <- query.Result{Result: users}
+	}()
+
+	return result
+}
+
+func scanUser(row pgx.Row) (storage.User, error) {
+	user := storage.User{}
+	role := ""
+	err := row.Scan(
+		&user.ID,
+		&user.Name,
+		&user.Nickname,
+		&user.Email,
+		&user.Password,
+		&user.Bio,
+		&user.Image,
+		&user.CreatedDate,
+		&user.MFASecret,
+		&user.MFAEnabledAt,
+		&user.MFALastStep,
+		&role,
+		&user.SuspendedAt,
+		&user.PasswordResetRequired,
+		&user.DeletionScheduledAt,
+	)
+	user.Role = rbac.Role(role)
+	return user, err
+}
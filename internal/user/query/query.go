@@ -2,17 +2,90 @@ package query
 
 import (
 	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenNotFound is returned when the refresh token has not been persisted.
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrSessionNotFound is returned when the requested session does not exist.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrAccountNotFound is returned when no user has the email.
+	ErrAccountNotFound = errors.New("account not found")
+	// ErrIncorrectPassword is returned when the password does not match the user's.
+	ErrIncorrectPassword = errors.New("incorrect password")
+	// ErrTokenNotFound is returned when the key is not in the token store, or has expired.
+	ErrTokenNotFound = errors.New("data not found")
+	// ErrIdentityNotFound is returned when the subject of the provider is not linked to a user.
+	ErrIdentityNotFound = errors.New("identity not found")
+	// ErrAPIKeyNotFound is returned when the requested api key does not exist.
+	ErrAPIKeyNotFound = errors.New("api key not found")
 )
 
 type UserQuery interface {
 	FindByEmail(ctx context.Context, email string) <-chan Result
 	FindByEmailAndPassword(ctx context.Context, email, password string) <-chan Result
+	FindByID(ctx context.Context, id string) <-chan Result
+	// NicknameExists reports whether a user has the nickname.
+	NicknameExists(ctx context.Context, nickname string) <-chan Result
+	// FindAll returns a page of the users whose email, name or nickname contains
+	// the search, with the role, the most recent first, empty filters match every user.
+	FindAll(ctx context.Context, search, role string, limit, offset int) <-chan Result
+	// FindDueForDeletion returns the users whose deletion has been scheduled at or before the time.
+	FindDueForDeletion(ctx context.Context, at time.Time) <-chan Result
 }
 
 type TokenQuery interface {
 	Find(key string) <-chan Result
 }
 
+type RefreshTokenQuery interface {
+	FindByID(ctx context.Context, id string) <-chan Result
+	// FindByAccessTokenID returns the refresh token issued along with the access token.
+	FindByAccessTokenID(ctx context.Context, accessTokenID string) <-chan Result
+}
+
+// RevokedTokenQuery reads the list of access tokens revoked before they expire.
+type RevokedTokenQuery interface {
+	IsRevoked(ctx context.Context, id string) <-chan Result
+}
+
+// SessionQuery reads the logins of the users.
+type SessionQuery interface {
+	FindByID(ctx context.Context, id string) <-chan Result
+	// FindActiveByUser returns the sessions of the user that have
+	// neither been revoked nor expired, the most recently seen first.
+	FindActiveByUser(ctx context.Context, userID string) <-chan Result
+}
+
+// IdentityQuery reads the identities of OpenID Connect providers linked to the users.
+type IdentityQuery interface {
+	FindBySubject(ctx context.Context, provider, subject string) <-chan Result
+}
+
+// APIKeyQuery reads the api keys of the users.
+type APIKeyQuery interface {
+	FindByID(ctx context.Context, id string) <-chan Result
+	// FindByHash returns the api key with the hash, along with the email of its user.
+	FindByHash(ctx context.Context, hash []byte) <-chan Result
+	FindByUser(ctx context.Context, userID string) <-chan Result
+}
+
+// AuditEventQuery reads the audit trail.
+type AuditEventQuery interface {
+	// FindAll returns a page of the events the user is the actor or the target of,
+	// with the action, the most recent first, empty filters match every event.
+	FindAll(ctx context.Context, userID, action string, limit, offset int) <-chan Result
+}
+
+// ExportQuery reads what the other modules hold about a user, for the export of their data.
+type ExportQuery interface {
+	FindPosts(ctx context.Context, userID string) <-chan Result
+	FindComments(ctx context.Context, userID string) <-chan Result
+	FindFavorites(ctx context.Context, userID string) <-chan Result
+}
+
 type Result struct {
 	Result interface{}
 	Error  error
@@ -0,0 +1,69 @@
+// Package rbac defines the roles of the users and what they are allowed to do.
+package rbac
+
+// Role is the role of a user, it is stored on the users and embedded in their tokens.
+type Role string
+
+const (
+	// RoleAdmin manages the users, and is allowed everything an editor is.
+	RoleAdmin Role = "admin"
+	// RoleEditor edits and publishes the posts of every author, and moderates their comments.
+	RoleEditor Role = "editor"
+	// RoleAuthor writes their own posts.
+	RoleAuthor Role = "author"
+	// RoleReader only reads, comments and favorites the posts.
+	RoleReader Role = "reader"
+
+	// DefaultRole is the role of the new users, including the ones signing in
+	// with an email link or an OpenID Connect provider, an admin makes them authors.
+	DefaultRole = RoleReader
+)
+
+// Permission is an action that is only allowed to some roles.
+type Permission string
+
+const (
+	// PermissionWritePosts allows writing, publishing and deleting one's own posts.
+	PermissionWritePosts Permission = "posts:write"
+	// PermissionEditAnyPost allows doing so with the posts of the other authors.
+	PermissionEditAnyPost Permission = "posts:edit_any"
+	// PermissionModerateComments allows moderating the comments of every post.
+	PermissionModerateComments Permission = "comments:moderate"
+	// PermissionManageUsers allows managing the users and their roles.
+	PermissionManageUsers Permission = "users:manage"
+)
+
+// permissions are the permissions of each role.
+var permissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionWritePosts,
+		PermissionEditAnyPost,
+		PermissionModerateComments,
+		PermissionManageUsers,
+	},
+	RoleEditor: {
+		PermissionWritePosts,
+		PermissionEditAnyPost,
+		PermissionModerateComments,
+	},
+	RoleAuthor: {
+		PermissionWritePosts,
+	},
+	RoleReader: {},
+}
+
+// IsRole reports whether the role exists.
+func IsRole(role string) bool {
+	_, ok := permissions[Role(role)]
+	return ok
+}
+
+// Can reports whether the role has the permission.
+func (r Role) Can(permission Permission) bool {
+	for _, p := range permissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
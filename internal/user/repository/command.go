@@ -2,13 +2,28 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"github.com/SemmiDev/blog/internal/user/entity"
 	"time"
 )
 
+var (
+	// ErrRefreshTokenUsed is returned when a refresh token is used
+	// after it has already been used or revoked.
+	ErrRefreshTokenUsed = errors.New("refresh token has already been used")
+	// ErrMFAStepUsed is returned when a totp code is used twice.
+	ErrMFAStepUsed = errors.New("totp code has already been used")
+	// ErrRecoveryCodeNotFound is returned when the recovery code does not
+	// belong to the user, or has already been used.
+	ErrRecoveryCodeNotFound = errors.New("recovery code not found")
+	// ErrNicknameTaken is returned when another user has taken the nickname in the meantime.
+	ErrNicknameTaken = errors.New("nickname already taken")
+)
+
 type UserCommand interface {
 	UserSaver
 	UserUpdater
+	UserDeleter
 }
 
 type UserSaver interface {
@@ -16,17 +31,92 @@ type UserSaver interface {
 }
 
 type UserUpdater interface {
+	// UpdatePassword writes the password of the user and whether it has to be reset.
 	UpdatePassword(ctx context.Context, arg *entity.User) <-chan error
 	UpdateBio(ctx context.Context, arg *entity.User) <-chan error
 	UpdateImage(ctx context.Context, arg *entity.User) <-chan error
+	// UpdateMFA writes the totp secret of the user and whether it is enabled.
+	UpdateMFA(ctx context.Context, arg *entity.User) <-chan error
+	// UseMFAStep records the step of the totp code the user has logged in with,
+	// a step that is not newer than the last one gets ErrMFAStepUsed.
+	UseMFAStep(ctx context.Context, userID string, step int64) <-chan error
+	// UpdateRole writes the role of the user.
+	UpdateRole(ctx context.Context, arg *entity.User) <-chan error
+	// UpdateSuspension writes whether the user has been suspended.
+	UpdateSuspension(ctx context.Context, arg *entity.User) <-chan error
+	// UpdateDeletion writes when the account of the user is deleted.
+	UpdateDeletion(ctx context.Context, arg *entity.User) <-chan error
+}
+
+// UserDeleter deletes the users along with everything they own.
+type UserDeleter interface {
+	Delete(ctx context.Context, id string) <-chan error
 }
 
 type TokenCommand interface {
 	Set(key string, val []byte, exp time.Duration) <-chan error
 	Delete(key string) <-chan error
+	// Increment adds one to the counter of the key, see memory.Store.
+	Increment(key string, exp time.Duration) <-chan Result
+}
+
+type RefreshTokenCommand interface {
+	Save(ctx context.Context, arg *entity.RefreshToken) <-chan error
+	// Use marks the refresh token as used at the given time,
+	// only one of the concurrent uses succeeds, the others get ErrRefreshTokenUsed.
+	Use(ctx context.Context, id string, at time.Time) <-chan error
+	// RevokeFamily revokes every refresh token of the family,
+	// and the access tokens issued along with them.
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) <-chan error
+	// RevokeUser revokes every refresh token of the user,
+	// and the access tokens issued along with them.
+	RevokeUser(ctx context.Context, userID string, at time.Time) <-chan error
+}
+
+// RevokedTokenCommand writes the list of access tokens revoked before they expire.
+type RevokedTokenCommand interface {
+	// Revoke lists the access token until it expires on its own.
+	Revoke(ctx context.Context, id string, expiredAt time.Time) <-chan error
+}
+
+// SessionCommand writes the logins of the users.
+// a session is revoked along with its refresh tokens, see RefreshTokenCommand.
+type SessionCommand interface {
+	Save(ctx context.Context, arg *entity.Session) <-chan error
+	Update(ctx context.Context, arg *entity.Session) <-chan error
+	// See records the activity of the session the access token has been issued in,
+	// at most once a minute.
+	See(ctx context.Context, accessTokenID, ip string, at time.Time) <-chan error
+}
+
+// RecoveryCodeCommand writes the recovery codes of two-factor authentication,
+// only their hashes are stored.
+type RecoveryCodeCommand interface {
+	// Replace replaces the recovery codes of the user, nil removes them.
+	Replace(ctx context.Context, userID string, hashes [][]byte) <-chan error
+	// Use marks the recovery code as used, or returns ErrRecoveryCodeNotFound.
+	Use(ctx context.Context, userID string, hash []byte, at time.Time) <-chan error
+}
+
+// IdentityCommand writes the identities of OpenID Connect providers linked to the users.
+type IdentityCommand interface {
+	Save(ctx context.Context, arg *entity.Identity) <-chan error
+}
+
+// APIKeyCommand writes the api keys of the users.
+type APIKeyCommand interface {
+	Save(ctx context.Context, arg *entity.APIKey) <-chan error
+	Delete(ctx context.Context, id string) <-chan error
+	// See records the use of the api key, at most once a minute.
+	See(ctx context.Context, id string, at time.Time) <-chan error
 }
 
 type Result struct {
 	Result interface{}
 	Error  error
 }
+
+// AuditEventCommand appends the events of the audit trail.
+type AuditEventCommand interface {
+	Save(ctx context.Context, arg *entity.AuditEvent) <-chan error
+}
@@ -2,14 +2,15 @@ package memory
 
 import (
 	"github.com/SemmiDev/blog/internal/common/memory"
+	"github.com/SemmiDev/blog/internal/user/repository"
 	"time"
 )
 
 type TokenCommandMemory struct {
-	DB *memory.Storage
+	DB memory.Store
 }
 
-func NewTokenCommandMemory(DB *memory.Storage) *TokenCommandMemory {
+func NewTokenCommandMemory(DB memory.Store) *TokenCommandMemory {
 	return &TokenCommandMemory{DB: DB}
 }
 
@@ -44,3 +45,21 @@ func (t *TokenCommandMemory) Delete(key string) <-chan error {
 
 	return result
 }
+
+func (t *TokenCommandMemory) Increment(key string, exp time.Duration) <-chan repository.Result {
+	result := make(chan repository.Result)
+
+	go func() {
+		defer close(result)
+
+		counter, err := t.DB.Increment(key, exp)
+		if err != nil {
+			result <- repository.Result{Error: err}
+			return
+		}
+
+		result <- repository.Result{Result: counter}
+	}()
+
+	return result
+}
@@ -0,0 +1,63 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type APIKeyCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewAPIKeyCommandPostgresql(DB *pgxpool.Pool) *APIKeyCommandPostgresql {
+	return &APIKeyCommandPostgresql{DB: DB}
+}
+
+func (a *APIKeyCommandPostgresql) Save(ctx context.Context, arg *entity.APIKey) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := a.DB.Exec(ctx, `INSERT INTO api_keys
+			(id, user_id, name, prefix, key_hash, scopes, expired_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			arg.ID, arg.UserID, arg.Name, arg.Prefix, arg.KeyHash, arg.Scopes, arg.ExpiredAt, arg.CreatedDate)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (a *APIKeyCommandPostgresql) Delete(ctx context.Context, id string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := a.DB.Exec(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (a *APIKeyCommandPostgresql) See(ctx context.Context, id string, at time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := a.DB.Exec(ctx, `UPDATE api_keys SET last_used_at = $2
+			WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $3)`,
+			id, at, at.Add(-seenInterval))
+
+		result <- err
+	}()
+
+	return result
+}
@@ -0,0 +1,32 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type AuditEventCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewAuditEventCommandPostgresql(DB *pgxpool.Pool) *AuditEventCommandPostgresql {
+	return &AuditEventCommandPostgresql{DB: DB}
+}
+
+func (a *AuditEventCommandPostgresql) Save(ctx context.Context, arg *entity.AuditEvent) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := a.DB.Exec(ctx, `INSERT INTO audit_events
+			(id, actor_id, action, target_id, ip, user_agent, metadata, created_at)
+			VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, $6, $7, $8)`,
+			arg.ID, arg.ActorID, string(arg.Action), arg.TargetID, arg.IP, arg.UserAgent, arg.Metadata, arg.CreatedDate)
+
+		result <- err
+	}()
+
+	return result
+}
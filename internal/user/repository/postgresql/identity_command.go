@@ -0,0 +1,31 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type IdentityCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewIdentityCommandPostgresql(DB *pgxpool.Pool) *IdentityCommandPostgresql {
+	return &IdentityCommandPostgresql{DB: DB}
+}
+
+func (i *IdentityCommandPostgresql) Save(ctx context.Context, arg *entity.Identity) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := i.DB.Exec(ctx, `INSERT INTO identities (provider, subject, user_id, created_at)
+			VALUES ($1, $2, $3, $4)`,
+			arg.Provider, arg.Subject, arg.UserID, arg.CreatedDate)
+
+		result <- err
+	}()
+
+	return result
+}
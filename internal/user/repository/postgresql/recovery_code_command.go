@@ -0,0 +1,73 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/repository"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type RecoveryCodeCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewRecoveryCodeCommandPostgresql(DB *pgxpool.Pool) *RecoveryCodeCommandPostgresql {
+	return &RecoveryCodeCommandPostgresql{DB: DB}
+}
+
+func (r *RecoveryCodeCommandPostgresql) Replace(ctx context.Context, userID string, hashes [][]byte) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		tx, err := r.DB.Begin(ctx)
+		if err != nil {
+			result <- err
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		for _, hash := range hashes {
+			_, err = tx.Exec(ctx, `INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES ($1, $2)`,
+				userID, hash)
+			if err != nil {
+				result <- err
+				return
+			}
+		}
+
+		result <- tx.Commit(ctx)
+	}()
+
+	return result
+}
+
+func (r *RecoveryCodeCommandPostgresql) Use(ctx context.Context, userID string, hash []byte, at time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		tag, err := r.DB.Exec(ctx, `UPDATE mfa_recovery_codes SET used_at = $3
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`, userID, hash, at)
+		if err != nil {
+			result <- err
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			result <- repository.ErrRecoveryCodeNotFound
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
@@ -0,0 +1,109 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/SemmiDev/blog/internal/user/repository"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type RefreshTokenCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewRefreshTokenCommandPostgresql(DB *pgxpool.Pool) *RefreshTokenCommandPostgresql {
+	return &RefreshTokenCommandPostgresql{DB: DB}
+}
+
+func (r *RefreshTokenCommandPostgresql) Save(ctx context.Context, arg *entity.RefreshToken) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := r.DB.Exec(ctx, `INSERT INTO refresh_tokens
+			(id, family_id, user_id, access_token_id, access_expired_at, expired_at, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			arg.ID, arg.FamilyID, arg.UserID, arg.AccessTokenID, arg.AccessExpiredAt, arg.ExpiredAt, arg.CreatedDate)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (r *RefreshTokenCommandPostgresql) Use(ctx context.Context, id string, at time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		tag, err := r.DB.Exec(ctx, `UPDATE refresh_tokens SET used_at = $2
+			WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL`, id, at)
+		if err != nil {
+			result <- err
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			result <- repository.ErrRefreshTokenUsed
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
+
+func (r *RefreshTokenCommandPostgresql) RevokeFamily(ctx context.Context, familyID string, at time.Time) <-chan error {
+	return r.revoke(ctx, `family_id = $1`, familyID, at)
+}
+
+func (r *RefreshTokenCommandPostgresql) RevokeUser(ctx context.Context, userID string, at time.Time) <-chan error {
+	return r.revoke(ctx, `user_id = $1`, userID, at)
+}
+
+// revoke revokes the refresh tokens matching the condition and their sessions,
+// and lists the access tokens issued along with them that have not expired yet.
+func (r *RefreshTokenCommandPostgresql) revoke(ctx context.Context, condition string, arg string, at time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		tx, err := r.DB.Begin(ctx)
+		if err != nil {
+			result <- err
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `INSERT INTO revoked_tokens (id, expired_at)
+			SELECT access_token_id, access_expired_at FROM refresh_tokens
+			WHERE `+condition+` AND access_token_id IS NOT NULL AND access_expired_at > $2
+			ON CONFLICT (id) DO NOTHING`, arg, at)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE sessions SET revoked_at = $2
+			WHERE id IN (SELECT family_id FROM refresh_tokens WHERE `+condition+`) AND revoked_at IS NULL`, arg, at)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		_, err = tx.Exec(ctx, `UPDATE refresh_tokens SET revoked_at = $2
+			WHERE `+condition+` AND revoked_at IS NULL`, arg, at)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		result <- tx.Commit(ctx)
+	}()
+
+	return result
+}
@@ -0,0 +1,38 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+type RevokedTokenCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewRevokedTokenCommandPostgresql(DB *pgxpool.Pool) *RevokedTokenCommandPostgresql {
+	return &RevokedTokenCommandPostgresql{DB: DB}
+}
+
+// Revoke lists the access token, the entries of the tokens
+// that have expired since are removed along the way.
+func (r *RevokedTokenCommandPostgresql) Revoke(ctx context.Context, id string, expiredAt time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := r.DB.Exec(ctx, `INSERT INTO revoked_tokens (id, expired_at) VALUES ($1, $2)
+			ON CONFLICT (id) DO NOTHING`, id, expiredAt)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		_, err = r.DB.Exec(ctx, `DELETE FROM revoked_tokens WHERE expired_at <= $1`, time.Now())
+
+		result <- err
+	}()
+
+	return result
+}
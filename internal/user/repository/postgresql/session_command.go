@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"time"
+)
+
+// seenInterval is how often the activity of a session is written at most.
+const seenInterval = time.Minute
+
+type SessionCommandPostgresql struct {
+	DB *pgxpool.Pool
+}
+
+func NewSessionCommandPostgresql(DB *pgxpool.Pool) *SessionCommandPostgresql {
+	return &SessionCommandPostgresql{DB: DB}
+}
+
+func (s *SessionCommandPostgresql) Save(ctx context.Context, arg *entity.Session) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := s.DB.Exec(ctx, `INSERT INTO sessions
+			(id, user_id, user_agent, ip, created_at, last_seen_at, expired_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			arg.ID, arg.UserID, arg.UserAgent, arg.IP, arg.CreatedDate, arg.LastSeenAt, arg.ExpiredAt)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (s *SessionCommandPostgresql) Update(ctx context.Context, arg *entity.Session) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := s.DB.Exec(ctx, `UPDATE sessions
+			SET user_agent = $2, ip = $3, last_seen_at = $4, expired_at = $5
+			WHERE id = $1`,
+			arg.ID, arg.UserAgent, arg.IP, arg.LastSeenAt, arg.ExpiredAt)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (s *SessionCommandPostgresql) See(ctx context.Context, accessTokenID, ip string, at time.Time) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := s.DB.Exec(ctx, `UPDATE sessions SET last_seen_at = $3, ip = $2
+			WHERE id = (SELECT family_id FROM refresh_tokens WHERE access_token_id = $1)
+				AND last_seen_at < $4`,
+			accessTokenID, ip, at, at.Add(-seenInterval))
+
+		result <- err
+	}()
+
+	return result
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/SemmiDev/blog/internal/user/repository"
+	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v4/pgxpool"
 )
 
@@ -19,24 +21,23 @@ func (u *UserCommandPostgresql) Save(ctx context.Context, arg *entity.User) <-ch
 	result := make(chan error)
 
 	go func() {
+		defer close(result)
+
 		count := 0
 		err := u.DB.QueryRow(ctx, `SELECT COUNT(*) FROM users WHERE email = $1`, arg.Email).Scan(&count)
 		if err != nil {
 			result <- err
+			return
 		}
 
 		if count > 0 {
 			result <- errors.New("account already exists")
-		} else {
-			_, err := u.DB.Exec(ctx, `INSERT INTO users (id, name, nickname, email, password) VALUES ($1, $2, $3, $4, $5)`,
-				arg.ID, arg.Name, arg.Nickname, arg.Email, arg.Password)
-			if err != nil {
-				result <- err
-			}
+			return
 		}
 
-		result <- nil
-		close(result)
+		_, err = u.DB.Exec(ctx, `INSERT INTO users (id, name, nickname, email, password, role) VALUES ($1, $2, $3, $4, $5, $6)`,
+			arg.ID, arg.Name, arg.Nickname, arg.Email, arg.Password, arg.Role)
+		result <- nicknameError(err)
 	}()
 
 	return result
@@ -46,8 +47,8 @@ func (u *UserCommandPostgresql) UpdatePassword(ctx context.Context, arg *entity.
 	result := make(chan error)
 
 	go func() {
-		_, err := u.DB.Exec(ctx, `UPDATE users SET password = $2 WHERE id = $1`,
-			arg.ID, arg.Password)
+		_, err := u.DB.Exec(ctx, `UPDATE users SET password = $2, password_reset_required = $3 WHERE id = $1`,
+			arg.ID, arg.Password, arg.PasswordResetRequired)
 		if err != nil {
 			result <- err
 		}
@@ -90,3 +91,143 @@ func (u *UserCommandPostgresql) UpdateImage(ctx context.Context, arg *entity.Use
 
 	return result
 }
+
+func (u *UserCommandPostgresql) UpdateRole(ctx context.Context, arg *entity.User) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := u.DB.Exec(ctx, `UPDATE users SET role = $2 WHERE id = $1`, arg.ID, arg.Role)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
+
+func (u *UserCommandPostgresql) UpdateSuspension(ctx context.Context, arg *entity.User) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := u.DB.Exec(ctx, `UPDATE users SET suspended_at = $2 WHERE id = $1`, arg.ID, arg.SuspendedAt)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
+
+func (u *UserCommandPostgresql) UpdateDeletion(ctx context.Context, arg *entity.User) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := u.DB.Exec(ctx, `UPDATE users SET deletion_scheduled_at = $2 WHERE id = $1`, arg.ID, arg.DeletionScheduledAt)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
+
+// Delete deletes the user, their posts, favorites, sessions and keys go
+// along through the foreign keys. their comments are emptied and kept
+// without their user, so that the replies of the others are not lost.
+func (u *UserCommandPostgresql) Delete(ctx context.Context, id string) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		tx, err := u.DB.Begin(ctx)
+		if err != nil {
+			result <- err
+			return
+		}
+		defer tx.Rollback(ctx)
+
+		_, err = tx.Exec(ctx, `UPDATE comments SET content = '', status = 'removed', updated_at = NOW()
+			WHERE user_id = $1`, id)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		_, err = tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+		if err != nil {
+			result <- err
+			return
+		}
+
+		result <- tx.Commit(ctx)
+	}()
+
+	return result
+}
+
+func (u *UserCommandPostgresql) UpdateMFA(ctx context.Context, arg *entity.User) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		_, err := u.DB.Exec(ctx, `UPDATE users
+			SET mfa_secret = $2, mfa_enabled_at = $3, mfa_last_step = $4
+			WHERE id = $1`,
+			arg.ID, arg.MFASecret, arg.MFAEnabledAt, arg.MFALastStep)
+
+		result <- err
+	}()
+
+	return result
+}
+
+func (u *UserCommandPostgresql) UseMFAStep(ctx context.Context, userID string, step int64) <-chan error {
+	result := make(chan error)
+
+	go func() {
+		defer close(result)
+
+		// the condition makes concurrent logins with the same code race for one row.
+		tag, err := u.DB.Exec(ctx, `UPDATE users SET mfa_last_step = $2
+			WHERE id = $1 AND mfa_last_step < $2`, userID, step)
+		if err != nil {
+			result <- err
+			return
+		}
+		if tag.RowsAffected() == 0 {
+			result <- repository.ErrMFAStepUsed
+			return
+		}
+
+		result <- nil
+	}()
+
+	return result
+}
+
+// nicknameError turns the unique violation of the nickname, when another
+// user has taken it in the meantime, into repository.ErrNicknameTaken.
+func nicknameError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_nickname_key" {
+		return repository.ErrNicknameTaken
+	}
+	return err
+}
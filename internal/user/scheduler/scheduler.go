@@ -0,0 +1,55 @@
+package scheduler
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/common/logger"
+	"time"
+)
+
+// AccountDeleter deletes the accounts whose grace period is over at the time.
+type AccountDeleter interface {
+	DeleteDueAccounts(ctx context.Context, at time.Time) (int, error)
+}
+
+// Scheduler periodically deletes the accounts whose deletion has been scheduled.
+type Scheduler struct {
+	deleter  AccountDeleter
+	interval time.Duration
+	done     chan struct{}
+}
+
+// New creates a new Scheduler and starts it in the background.
+func New(deleter AccountDeleter) *Scheduler {
+	scheduler := &Scheduler{
+		deleter:  deleter,
+		interval: time.Minute,
+		done:     make(chan struct{}),
+	}
+	go scheduler.run()
+	return scheduler
+}
+
+func (s *Scheduler) Close() error {
+	s.done <- struct{}{}
+	return nil
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case t := <-ticker.C:
+			count, err := s.deleter.DeleteDueAccounts(context.Background(), t)
+			if err != nil {
+				logger.Log.Error().Interface("scheduler", err.Error()).Send()
+			}
+			if count > 0 {
+				logger.Log.Info().Int("deleted", count).Msg("scheduled account deletions carried out")
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package server
+
+import (
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	"github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"github.com/gofiber/fiber/v2"
+	"net/http"
+)
+
+// MountAdmin mounts the user management routes of the admins to the fiber router.
+// the users can not be managed with an api key.
+func (s *UserServer) MountAdmin(r fiber.Router) {
+	r.Use(s.AuthMiddleware(), RequirePermission(rbac.PermissionManageUsers))
+
+	r.Get("/users", s.FindAccountsHandler)
+	r.Get("/users/:id", s.FindAccountHandler)
+	r.Put("/users/:id/suspend", s.SuspendUserHandler)
+	r.Put("/users/:id/unsuspend", s.UnsuspendUserHandler)
+	r.Put("/users/:id/password/reset", s.ForcePasswordResetHandler)
+	r.Put("/users/:id/role", s.ChangeRoleHandler)
+	r.Delete("/users/:id", s.DeleteUserHandler)
+
+	r.Get("/audit-events", s.FindAuditEventsHandler)
+}
+
+// FindAccountsHandler returns the users page by page,
+// filtered by a search on their email, name or nickname and by their role.
+func (s *UserServer) FindAccountsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	accounts, err := s.AdminService.FindAccounts(c.Context(), c.Query("q"), c.Query("role"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": accounts,
+	})
+}
+
+// FindAccountHandler returns a user by id.
+func (s *UserServer) FindAccountHandler(c *fiber.Ctx) error {
+	account, err := s.AdminService.FindAccount(c.Context(), c.Params("id"))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
+
+// SuspendUserHandler suspends a user, the reason is kept in the audit trail.
+func (s *UserServer) SuspendUserHandler(c *fiber.Ctx) error {
+	reason := c.FormValue("reason")
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	account, err := s.AdminService.SuspendUser(c.Context(), c.Params("id"), reason, payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
+
+// UnsuspendUserHandler lifts the suspension of a user.
+func (s *UserServer) UnsuspendUserHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	account, err := s.AdminService.UnsuspendUser(c.Context(), c.Params("id"), payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
+
+// ForcePasswordResetHandler makes a user reset their password before they log in again.
+func (s *UserServer) ForcePasswordResetHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	account, err := s.AdminService.ForcePasswordReset(c.Context(), c.Params("id"), payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
+
+// ChangeRoleHandler changes the role of a user.
+func (s *UserServer) ChangeRoleHandler(c *fiber.Ctx) error {
+	role := c.FormValue("role")
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	account, err := s.AdminService.ChangeRole(c.Context(), c.Params("id"), role, payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
+
+// DeleteUserHandler deletes a user along with everything they own.
+func (s *UserServer) DeleteUserHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.AdminService.DeleteUser(c.Context(), c.Params("id"), payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// FindAuditEventsHandler returns the whole audit trail page by page,
+// filtered by the user who is the actor or the target, and by the action.
+func (s *UserServer) FindAuditEventsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	events, err := s.AdminService.FindAuditEvents(c.Context(), c.Query("user_id"), c.Query("action"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": events,
+	})
+}
@@ -1,18 +1,18 @@
 package server
 
 import (
+	"fmt"
+	"github.com/SemmiDev/blog/config"
 	"github.com/SemmiDev/blog/internal/user/helper"
 	"github.com/SemmiDev/blog/internal/user/service"
 
+	"github.com/SemmiDev/blog/internal/common/mail"
 	"github.com/SemmiDev/blog/internal/common/memory"
-	queryMemory "github.com/SemmiDev/blog/internal/user/query/memory"
-	queryPostgresql "github.com/SemmiDev/blog/internal/user/query/postgresql"
-	commandMemory "github.com/SemmiDev/blog/internal/user/repository/memory"
-	commandPostgresql "github.com/SemmiDev/blog/internal/user/repository/postgresql"
-	"github.com/SemmiDev/blog/internal/user/token"
+	"github.com/SemmiDev/blog/internal/common/oidc"
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"net/http"
+	"strings"
 )
 
 // AuthServer is the struct that contains UserService.
@@ -22,27 +22,69 @@ type AuthServer struct {
 }
 
 // NewAuthServer creates a new AuthServer.
-func NewAuthServer(db *pgxpool.Pool, tokenMaker token.Maker) (*AuthServer, error) {
-	// for now, we're using memory repository for stores code verification.
-	// in the future, we'll use redis, or other stores.
-	m := memory.New()
+func NewAuthServer(userService service.UserService) *AuthServer {
+	return &AuthServer{UserService: userService}
+}
+
+// NewOIDCProviders creates the OpenID Connect providers of the OIDC_PROVIDERS config,
+// by name. their discovery documents are fetched when they are first used.
+func NewOIDCProviders() map[string]*oidc.Provider {
+	siteURL := strings.TrimSuffix(config.Env.SiteURL, "/")
+
+	providers := map[string]*oidc.Provider{}
+	for _, p := range config.Env.OIDCProviderList() {
+		redirectURL := siteURL + "/auth/oidc/" + p.Name + "/callback"
+		providers[p.Name] = oidc.NewProvider(p.Name, p.Issuer, p.ClientID, p.ClientSecret, redirectURL)
+	}
+	return providers
+}
 
-	userServiceImpl := &service.UserServiceImpl{
-		UserQuery:    queryPostgresql.NewUserQueryPostgresql(db),
-		TokenQuery:   queryMemory.NewTokenQueryMemory(m),
-		UserCommand:  commandPostgresql.NewUserCommandPostgresql(db),
-		TokenCommand: commandMemory.NewTokenCommandMemory(m),
-		TokenMaker:   tokenMaker,
+// NewVerificationStore creates the store of verification codes chosen by the
+// VERIFICATION_STORE config, "postgres" or "redis" share the codes between the
+// instances, "memory" keeps them in the process and only fits a single instance.
+func NewVerificationStore(db *pgxpool.Pool) (memory.Store, error) {
+	switch config.Env.VerificationStore {
+	case "postgres", "":
+		return memory.NewPostgres(db), nil
+	case "redis":
+		return memory.NewRedis(config.Env.RedisAddress, config.Env.RedisPassword,
+			config.Env.RedisDB, "blog:verification:"), nil
+	case "memory":
+		return memory.New(), nil
+	default:
+		return nil, fmt.Errorf("unknown verification store %q", config.Env.VerificationStore)
 	}
+}
 
-	return &AuthServer{UserService: userServiceImpl}, nil
+// NewMailer creates the mailer chosen by the MAILER config,
+// "smtp" sends the emails, "file" writes them to MAIL_OUTBOX_DIR.
+func NewMailer() (mail.Mailer, error) {
+	switch config.Env.Mailer {
+	case "smtp":
+		return mail.NewSMTPMailer(config.Env.SMTPHost, config.Env.SMTPPort,
+			config.Env.SMTPUsername, config.Env.SMTPPassword, config.Env.MailFrom), nil
+	case "file", "":
+		return mail.NewFileMailer(config.Env.MailOutboxDir, config.Env.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", config.Env.Mailer)
+	}
 }
 
 // Mount mounts the auth server to the fiber app.
-func (s *AuthServer) Mount(r fiber.Router) {
+// authMiddleware guards the routes that need an authenticated user.
+func (s *AuthServer) Mount(r fiber.Router, authMiddleware fiber.Handler) {
 	r.Post("/register", s.RegisterHandler)
 	r.Post("/authorize", s.AuthorizeHandler)
+	r.Post("/refresh", s.RefreshHandler)
+	r.Post("/logout", authMiddleware, s.LogoutHandler)
+	r.Post("/logout/everywhere", authMiddleware, s.LogoutEverywhereHandler)
 	r.Post("/password/reset", s.ResetPasswordHandler)
+	r.Post("/unlock", s.UnlockHandler)
+	r.Post("/mfa/verify", s.VerifyMFAHandler)
+	r.Post("/login/link", s.SendLoginLinkHandler)
+	r.Get("/login/link", s.LoginWithLinkHandler)
+	r.Get("/oidc/:provider/login", s.StartOIDCHandler)
+	r.Get("/oidc/:provider/callback", s.OIDCCallbackHandler)
 }
 
 // RegisterHandler handles the registration of a new user.
@@ -59,7 +101,7 @@ func (s *AuthServer) RegisterHandler(c *fiber.Ctx) error {
 
 	code := c.Query("code")
 	if code != "" {
-		userAuth, err := s.UserService.RegisterNewUser(c.Context(), code, name, password)
+		userAuth, err := s.UserService.RegisterNewUser(c.Context(), email, code, name, password, Client(c))
 		if err != nil {
 			return helper.Error(c, err)
 		}
@@ -67,7 +109,7 @@ func (s *AuthServer) RegisterHandler(c *fiber.Ctx) error {
 			"data": userAuth,
 		})
 	}
-	err := s.UserService.SendVerificationCode(c.Context(), email, "registration")
+	err := s.UserService.SendVerificationCode(c.Context(), email, "registration", Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
@@ -81,7 +123,22 @@ func (s *AuthServer) AuthorizeHandler(c *fiber.Ctx) error {
 	email := c.FormValue("email")
 	password := c.FormValue("password")
 
-	userAuth, err := s.UserService.Authorize(c.Context(), email, password)
+	userAuth, err := s.UserService.Authorize(c.Context(), email, password, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": userAuth,
+	})
+}
+
+// RefreshHandler exchanges a refresh token for a new pair of tokens.
+// the refresh token can only be exchanged once.
+func (s *AuthServer) RefreshHandler(c *fiber.Ctx) error {
+	refreshToken := c.FormValue("refresh_token")
+
+	userAuth, err := s.UserService.RefreshToken(c.Context(), refreshToken, Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
@@ -91,6 +148,32 @@ func (s *AuthServer) AuthorizeHandler(c *fiber.Ctx) error {
 	})
 }
 
+// LogoutHandler revokes the access token of the request and its login.
+func (s *AuthServer) LogoutHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.UserService.Logout(c.Context(), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// LogoutEverywhereHandler revokes every login of the authenticated user.
+func (s *AuthServer) LogoutEverywhereHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.UserService.LogoutEverywhere(c.Context(), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
 // ResetPasswordHandler handles password reset request.
 // if code in query is empty, it will send verification
 // code to the user's email for password reset.
@@ -102,16 +185,108 @@ func (s *AuthServer) ResetPasswordHandler(c *fiber.Ctx) error {
 
 	code := c.Query("code")
 	if code != "" {
-		err := s.UserService.ResetPassword(c.Context(), code, newPassword, newConfirmPassword)
+		err := s.UserService.ResetPassword(c.Context(), email, code, newPassword, newConfirmPassword, Client(c))
+		if err != nil {
+			return helper.Error(c, err)
+		}
+		return c.SendStatus(http.StatusOK)
+	}
+
+	err := s.UserService.SendVerificationCode(c.Context(), email, "reset-password", Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+// UnlockHandler handles unlocking an account locked after too many failed logins.
+// if code in query is empty, it will send an unlock code to the user's email.
+// if code in query is not empty, it will unlock the account.
+func (s *AuthServer) UnlockHandler(c *fiber.Ctx) error {
+	email := c.FormValue("email")
+
+	code := c.Query("code")
+	if code != "" {
+		err := s.UserService.UnlockAccount(c.Context(), email, code, Client(c))
 		if err != nil {
 			return helper.Error(c, err)
 		}
 		return c.SendStatus(http.StatusOK)
 	}
 
-	err := s.UserService.SendVerificationCode(c.Context(), email, "reset-password")
+	err := s.UserService.SendVerificationCode(c.Context(), email, "unlock", Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
 	return c.SendStatus(http.StatusOK)
 }
+
+// VerifyMFAHandler handles the second step of the authorization of a user
+// with two-factor authentication, it returns a token if the code is valid.
+func (s *AuthServer) VerifyMFAHandler(c *fiber.Ctx) error {
+	mfaToken := c.FormValue("mfa_token")
+	code := c.FormValue("code")
+
+	userAuth, err := s.UserService.VerifyMFA(c.Context(), mfaToken, code, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": userAuth,
+	})
+}
+
+// SendLoginLinkHandler sends a one-time sign-in link to the user's email.
+func (s *AuthServer) SendLoginLinkHandler(c *fiber.Ctx) error {
+	email := c.FormValue("email")
+
+	err := s.UserService.SendVerificationCode(c.Context(), email, "login", Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+// LoginWithLinkHandler handles the sign-in link sent by email,
+// it returns a token just like AuthorizeHandler.
+func (s *AuthServer) LoginWithLinkHandler(c *fiber.Ctx) error {
+	email := c.Query("email")
+	code := c.Query("code")
+
+	userAuth, err := s.UserService.LoginWithLink(c.Context(), email, code, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": userAuth,
+	})
+}
+
+// StartOIDCHandler redirects the user to the provider to sign in.
+func (s *AuthServer) StartOIDCHandler(c *fiber.Ctx) error {
+	authURL, err := s.UserService.StartOIDC(c.Context(), c.Params("provider"))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Redirect(authURL, http.StatusFound)
+}
+
+// OIDCCallbackHandler handles the user coming back from the provider,
+// it returns a token just like AuthorizeHandler.
+func (s *AuthServer) OIDCCallbackHandler(c *fiber.Ctx) error {
+	if c.Query("error") != "" {
+		return helper.Error(c, helper.NewErr(helper.ErrOIDCDeniedCode, "provider"))
+	}
+
+	userAuth, err := s.UserService.LoginWithOIDC(c.Context(), c.Params("provider"), c.Query("state"), c.Query("code"), Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": userAuth,
+	})
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// seenInterval is how often the activity of the same key is written at most.
+	seenInterval = time.Minute
+	// seenTimeout bounds a write of the activity.
+	seenTimeout = 5 * time.Second
+	// maxSeenWriters is how many writes of the activity run at the same time at most,
+	// the activity is dropped beyond, it is written on a later request.
+	maxSeenWriters = 8
+	// maxSeenKeys is how many keys are remembered before the stale ones are forgotten.
+	maxSeenKeys = 10000
+)
+
+// seenRecorder writes the activity of the sessions and api keys in the background,
+// so that the requests do not wait for it.
+type seenRecorder struct {
+	mu      sync.Mutex
+	last    map[string]time.Time
+	writers chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newSeenRecorder() *seenRecorder {
+	return &seenRecorder{
+		last:    map[string]time.Time{},
+		writers: make(chan struct{}, maxSeenWriters),
+	}
+}
+
+// record runs write in the background, unless the key has been recorded
+// within seenInterval or too many writes are already running.
+func (r *seenRecorder) record(key string, write func(ctx context.Context) error, onError func(err error)) {
+	if !r.due(key, time.Now()) {
+		return
+	}
+
+	select {
+	case r.writers <- struct{}{}:
+	default:
+		r.forget(key)
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() { <-r.writers }()
+
+		// the request may have completed, so the write has its own context.
+		ctx, cancel := context.WithTimeout(context.Background(), seenTimeout)
+		defer cancel()
+
+		err := write(ctx)
+		if err != nil {
+			r.forget(key)
+			onError(err)
+		}
+	}()
+}
+
+// due reports whether the key has to be recorded, and remembers it when so.
+func (r *seenRecorder) due(key string, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if last, ok := r.last[key]; ok && now.Sub(last) < seenInterval {
+		return false
+	}
+
+	if len(r.last) >= maxSeenKeys {
+		for k, last := range r.last {
+			if now.Sub(last) >= seenInterval {
+				delete(r.last, k)
+			}
+		}
+	}
+
+	r.last[key] = now
+	return true
+}
+
+// forget lets the key be recorded again on the next request.
+func (r *seenRecorder) forget(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.last, key)
+}
+
+// wait waits for the writes running in the background.
+func (r *seenRecorder) wait() {
+	r.wg.Wait()
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSeenRecorder(t *testing.T) {
+	r := newSeenRecorder()
+	var writes int32
+	write := func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("write has no deadline")
+		}
+		atomic.AddInt32(&writes, 1)
+		return nil
+	}
+	onError := func(err error) { t.Errorf("unexpected error %v", err) }
+
+	for i := 0; i < 10; i++ {
+		r.record("session:a", write, onError)
+		r.record("session:b", write, onError)
+	}
+	r.wait()
+
+	if got := atomic.LoadInt32(&writes); got != 2 {
+		t.Errorf("writes = %d, want one per key", got)
+	}
+}
+
+func TestSeenRecorderFailedWrite(t *testing.T) {
+	r := newSeenRecorder()
+	var failures int32
+	write := func(ctx context.Context) error { return errors.New("database down") }
+	onError := func(err error) { atomic.AddInt32(&failures, 1) }
+
+	// a failed write is tried again on the next request.
+	r.record("session:a", write, onError)
+	r.wait()
+	r.record("session:a", write, onError)
+	r.wait()
+
+	if got := atomic.LoadInt32(&failures); got != 2 {
+		t.Errorf("failures = %d, want 2", got)
+	}
+}
+
+func TestSeenRecorderBound(t *testing.T) {
+	r := newSeenRecorder()
+	release := make(chan struct{})
+	var started int32
+	write := func(ctx context.Context) error {
+		atomic.AddInt32(&started, 1)
+		<-release
+		return nil
+	}
+
+	for i := 0; i < maxSeenWriters*2; i++ {
+		r.record(string(rune('a'+i)), write, func(err error) {})
+	}
+	close(release)
+	r.wait()
+
+	if got := atomic.LoadInt32(&started); got != maxSeenWriters {
+		t.Errorf("started = %d, want %d", got, maxSeenWriters)
+	}
+}
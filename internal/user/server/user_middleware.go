@@ -1,8 +1,14 @@
 package server
 
 import (
+	"context"
+	"github.com/SemmiDev/blog/internal/common/logger"
 	"github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
 	"strings"
 )
 
@@ -13,7 +19,9 @@ const (
 )
 
 // AuthMiddleware is a middleware that checks if the user is authenticated.
-func (s *UserServer) AuthMiddleware() fiber.Handler {
+// the user is authenticated by a token, or by an api key which has one of the
+// scopes, without scopes api keys are not accepted.
+func (s *UserServer) AuthMiddleware(scopes ...token.Scope) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// get the authorization header based on the authorizationHeaderKey
 		authorizationHeader := c.Get(authorizationHeaderKey)
@@ -33,10 +41,17 @@ func (s *UserServer) AuthMiddleware() fiber.Handler {
 			return helper.Error(c, helper.NewErr(helper.ErrAuthorizationTypeBearerCode, authorizationTypeBearer))
 		}
 
-		// get the authorization payload from the fields index 1
-		payload, err := s.TokenMaker.VerifyToken(fields[1])
+		// get the authorization payload from the fields index 1,
+		// api keys are told apart from the tokens by their prefix.
+		var payload *token.Payload
+		var err error
+		if strings.HasPrefix(fields[1], token.APIKeyPrefix) {
+			payload, err = s.verifyAPIKey(c, fields[1], scopes)
+		} else {
+			payload, err = s.verifyToken(c, fields[1])
+		}
 		if err != nil {
-			return helper.Error(c, helper.NewErr(helper.ErrAuthorizationInvalidTokenCode, "authorization_token"))
+			return helper.Error(c, err)
 		}
 
 		// set the payload to the context.
@@ -45,3 +60,116 @@ func (s *UserServer) AuthMiddleware() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// verifyToken returns the payload of the paseto token.
+func (s *UserServer) verifyToken(c *fiber.Ctx, accessToken string) (*token.Payload, error) {
+	payload, err := s.TokenMaker.VerifyToken(accessToken)
+	if err != nil {
+		return nil, helper.NewErr(helper.ErrAuthorizationInvalidTokenCode, "authorization_token")
+	}
+
+	// reject the token if it has been revoked by logging out.
+	revoked, err := s.UserService.IsTokenRevoked(c.Context(), payload.ID)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, helper.NewErr(helper.ErrAuthorizationInvalidTokenCode, "authorization_token")
+	}
+
+	// record the activity of the session in the background,
+	// the request does not wait for it.
+	s.seeSession(payload.ID, c.IP())
+
+	return payload, nil
+}
+
+// verifyAPIKey returns the payload of the api key, if it has one of the scopes.
+func (s *UserServer) verifyAPIKey(c *fiber.Ctx, apiKey string, scopes []token.Scope) (*token.Payload, error) {
+	payload, err := s.UserService.VerifyAPIKey(c.Context(), apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := false
+	for _, scope := range scopes {
+		allowed = allowed || payload.HasScope(scope)
+	}
+	if !allowed {
+		return nil, helper.NewErr(helper.ErrInsufficientScopeCode, "authorization_token")
+	}
+
+	// record the use of the api key in the background.
+	s.seeAPIKey(payload.ID)
+
+	return payload, nil
+}
+
+// RequireRole is a middleware that only lets the users with one of the roles through,
+// it is mounted after AuthMiddleware, which sets the payload it reads the role from.
+func RequireRole(roles ...rbac.Role) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// get payload from context.
+		payload := AuthorizationPayload(c)
+		if payload == nil {
+			return helper.Error(c, helper.NewErr(helper.ErrAuthorizationHeaderKeyCode, authorizationHeaderKey))
+		}
+
+		for _, role := range roles {
+			if payload.Role == role {
+				return c.Next()
+			}
+		}
+		return helper.Error(c, helper.NewErr(helper.ErrForbiddenCode, "role"))
+	}
+}
+
+// RequirePermission is a middleware that only lets the users whose role has the
+// permission through, it is mounted after AuthMiddleware, like RequireRole.
+func RequirePermission(permission rbac.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// get payload from context.
+		payload := AuthorizationPayload(c)
+		if payload == nil {
+			return helper.Error(c, helper.NewErr(helper.ErrAuthorizationHeaderKeyCode, authorizationHeaderKey))
+		}
+
+		if !payload.Can(permission) {
+			return helper.Error(c, helper.NewErr(helper.ErrForbiddenCode, "role"))
+		}
+		return c.Next()
+	}
+}
+
+// AuthorizationPayload returns the token payload set by AuthMiddleware.
+// it returns nil when the request has not been authenticated.
+func AuthorizationPayload(c *fiber.Ctx) *token.Payload {
+	payload, _ := c.Context().UserValue(authorizationPayloadKey).(*token.Payload)
+	return payload
+}
+
+// Client returns the device the request comes from.
+func Client(c *fiber.Ctx) storage.Client {
+	return storage.Client{
+		UserAgent: utils.CopyString(c.Get(fiber.HeaderUserAgent)),
+		IP:        c.IP(),
+	}
+}
+
+// seeSession records the activity of the session, at most once a minute.
+func (s *UserServer) seeSession(accessTokenID, ip string) {
+	s.seen.record("session:"+accessTokenID, func(ctx context.Context) error {
+		return s.UserService.SeeSession(ctx, accessTokenID, ip)
+	}, func(err error) {
+		logger.Log.Error().Interface("session", err.Error()).Send()
+	})
+}
+
+// seeAPIKey records the use of the api key, at most once a minute.
+func (s *UserServer) seeAPIKey(id string) {
+	s.seen.record("api-key:"+id, func(ctx context.Context) error {
+		return s.UserService.SeeAPIKey(ctx, id)
+	}, func(err error) {
+		logger.Log.Error().Interface("api key", err.Error()).Send()
+	})
+}
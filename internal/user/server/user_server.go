@@ -4,34 +4,39 @@ import (
 	cloud "cloud.google.com/go/storage"
 	"context"
 	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/common/encryption"
 	zerolog "github.com/SemmiDev/blog/internal/common/logger"
 	"github.com/SemmiDev/blog/internal/common/memory"
+	"github.com/SemmiDev/blog/internal/common/pagination"
 	"github.com/SemmiDev/blog/internal/user/helper"
 	queryMemory "github.com/SemmiDev/blog/internal/user/query/memory"
 	queryPostgresql "github.com/SemmiDev/blog/internal/user/query/postgresql"
 	commandMemory "github.com/SemmiDev/blog/internal/user/repository/memory"
 	commandPostgresql "github.com/SemmiDev/blog/internal/user/repository/postgresql"
+	"github.com/SemmiDev/blog/internal/user/scheduler"
 	"github.com/SemmiDev/blog/internal/user/service"
 	"github.com/SemmiDev/blog/internal/user/token"
 	"github.com/gofiber/fiber/v2"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"google.golang.org/api/option"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // UserServer is a struct that contains UserService for interacting with the service.
 // and TokenMaker for generating & validating tokens.
 type UserServer struct {
-	UserService service.UserService
-	TokenMaker  token.Maker
+	UserService  service.UserService
+	AdminService service.AdminService
+	TokenMaker   token.Maker
+	seen         *seenRecorder
 }
 
-// NewUserServer returns a new UserServer.
-func NewUserServer(db *pgxpool.Pool, tokenMaker token.Maker) (*UserServer, error) {
-	// for now, we're using memory repository for stores code verification.
-	// in the future, we'll use redis, or other stores.
-	m := memory.New()
-
+// NewUserService creates the user service shared by the AuthServer and the UserServer,
+// and starts the scheduler which deletes the accounts once their grace period is over.
+// verification codes are kept in the store, which is shared between the instances.
+func NewUserService(db *pgxpool.Pool, tokenMaker token.Maker, m memory.Store) (*service.UserServiceImpl, error) {
 	// setup cloud client for interacting with google cloud storage.
 	// it will be used for storing user avatar/profile images.
 	opt := option.WithCredentialsFile(config.Env.FirebaseCredentialJSON)
@@ -41,22 +46,64 @@ func NewUserServer(db *pgxpool.Pool, tokenMaker token.Maker) (*UserServer, error
 		return nil, err
 	}
 
+	mailer, err := NewMailer()
+	if err != nil {
+		return nil, err
+	}
+
+	secretCipher, err := encryption.New(config.Env.MFAEncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
 	userServiceImpl := &service.UserServiceImpl{
-		UserQuery:    queryPostgresql.NewUserQueryPostgresql(db),
-		TokenQuery:   queryMemory.NewTokenQueryMemory(m),
-		UserCommand:  commandPostgresql.NewUserCommandPostgresql(db),
-		TokenCommand: commandMemory.NewTokenCommandMemory(m),
-		TokenMaker:   tokenMaker,
-		CloudStorage: cloudClient,
+		UserQuery:           queryPostgresql.NewUserQueryPostgresql(db),
+		TokenQuery:          queryMemory.NewTokenQueryMemory(m),
+		RefreshTokenQuery:   queryPostgresql.NewRefreshTokenQueryPostgresql(db),
+		RevokedTokenQuery:   queryPostgresql.NewRevokedTokenQueryPostgresql(db),
+		SessionQuery:        queryPostgresql.NewSessionQueryPostgresql(db),
+		IdentityQuery:       queryPostgresql.NewIdentityQueryPostgresql(db),
+		APIKeyQuery:         queryPostgresql.NewAPIKeyQueryPostgresql(db),
+		AuditEventQuery:     queryPostgresql.NewAuditEventQueryPostgresql(db),
+		ExportQuery:         queryPostgresql.NewExportQueryPostgresql(db),
+		UserCommand:         commandPostgresql.NewUserCommandPostgresql(db),
+		TokenCommand:        commandMemory.NewTokenCommandMemory(m),
+		RefreshTokenCommand: commandPostgresql.NewRefreshTokenCommandPostgresql(db),
+		RevokedTokenCommand: commandPostgresql.NewRevokedTokenCommandPostgresql(db),
+		SessionCommand:      commandPostgresql.NewSessionCommandPostgresql(db),
+		RecoveryCodeCommand: commandPostgresql.NewRecoveryCodeCommandPostgresql(db),
+		IdentityCommand:     commandPostgresql.NewIdentityCommandPostgresql(db),
+		APIKeyCommand:       commandPostgresql.NewAPIKeyCommandPostgresql(db),
+		AuditEventCommand:   commandPostgresql.NewAuditEventCommandPostgresql(db),
+		TokenMaker:          tokenMaker,
+		CloudStorage:        cloudClient,
+		Mailer:              mailer,
+		SecretCipher:        secretCipher,
+		OIDCProviders:       NewOIDCProviders(),
 	}
 
+	scheduler.New(userServiceImpl)
+
+	return userServiceImpl, nil
+}
+
+// NewUserServer returns a new UserServer.
+func NewUserServer(userServiceImpl *service.UserServiceImpl, tokenMaker token.Maker) *UserServer {
 	return &UserServer{
-		UserService: userServiceImpl,
-		TokenMaker:  tokenMaker,
-	}, nil
+		UserService:  userServiceImpl,
+		AdminService: userServiceImpl,
+		TokenMaker:   tokenMaker,
+		seen:         newSeenRecorder(),
+	}
+}
+
+// Close waits for the activity of the sessions and api keys being written in the background.
+func (s *UserServer) Close() {
+	s.seen.wait()
 }
 
 // Mount mounts the UserServer to the fiber app.
+// the account can not be managed with an api key.
 func (s *UserServer) Mount(r fiber.Router) {
 	// auth middleware for all routes.
 	r.Use(s.AuthMiddleware())
@@ -64,6 +111,23 @@ func (s *UserServer) Mount(r fiber.Router) {
 	r.Put("/password/change", s.ChangePasswordHandler)
 	r.Put("/profile/bio", s.ChangeBioHandler)
 	r.Put("/profile/image", s.ChangeImageHandler)
+
+	r.Get("/sessions", s.FindSessionsHandler)
+	r.Delete("/sessions/:id", s.RevokeSessionHandler)
+
+	r.Post("/mfa/enroll", s.EnrollMFAHandler)
+	r.Post("/mfa/confirm", s.ConfirmMFAHandler)
+	r.Post("/mfa/disable", s.DisableMFAHandler)
+
+	r.Get("/api-keys", s.FindAPIKeysHandler)
+	r.Post("/api-keys", s.CreateAPIKeyHandler)
+	r.Delete("/api-keys/:id", s.DeleteAPIKeyHandler)
+
+	r.Get("/audit-events", s.FindMyAuditEventsHandler)
+
+	r.Get("/account/export", s.ExportDataHandler)
+	r.Post("/account/deletion", s.ScheduleAccountDeletionHandler)
+	r.Delete("/account/deletion", s.CancelAccountDeletionHandler)
 }
 
 // ChangePasswordHandler changes the user's password.
@@ -75,7 +139,7 @@ func (s *UserServer) ChangePasswordHandler(c *fiber.Ctx) error {
 	// get payload from context.
 	payload := c.Context().UserValue(authorizationPayloadKey).(*token.Payload)
 
-	err := s.UserService.ChangePassword(c.Context(), oldPassword, newPassword, newConfirmPassword, payload.Email)
+	err := s.UserService.ChangePassword(c.Context(), oldPassword, newPassword, newConfirmPassword, payload.Email, Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
@@ -90,7 +154,7 @@ func (s *UserServer) ChangeBioHandler(c *fiber.Ctx) error {
 	// get payload from context.
 	payload := c.Context().UserValue(authorizationPayloadKey).(*token.Payload)
 
-	err := s.UserService.ChangeBio(c.Context(), bio, payload.Email)
+	err := s.UserService.ChangeBio(c.Context(), bio, payload.Email, Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
@@ -108,10 +172,220 @@ func (s *UserServer) ChangeImageHandler(c *fiber.Ctx) error {
 	// get payload from context.
 	payload := c.Context().UserValue(authorizationPayloadKey).(*token.Payload)
 
-	err = s.UserService.ChangeImage(c.Context(), file, payload.Email)
+	err = s.UserService.ChangeImage(c.Context(), file, payload.Email, Client(c))
 	if err != nil {
 		return helper.Error(c, err)
 	}
 
 	return c.SendStatus(http.StatusOK)
 }
+
+// FindSessionsHandler returns the devices the user is logged in on.
+func (s *UserServer) FindSessionsHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	sessions, err := s.UserService.FindSessions(c.Context(), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": sessions,
+	})
+}
+
+// RevokeSessionHandler logs the user out of one of their sessions.
+func (s *UserServer) RevokeSessionHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.UserService.RevokeSession(c.Context(), c.Params("id"), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// EnrollMFAHandler returns a new totp secret for the user's authenticator app.
+func (s *UserServer) EnrollMFAHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	enrollment, err := s.UserService.EnrollMFA(c.Context(), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": enrollment,
+	})
+}
+
+// ConfirmMFAHandler enables two-factor authentication, it returns the recovery codes.
+func (s *UserServer) ConfirmMFAHandler(c *fiber.Ctx) error {
+	code := c.FormValue("code")
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	recoveryCodes, err := s.UserService.ConfirmMFA(c.Context(), payload, code)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": fiber.Map{"recovery_codes": recoveryCodes},
+	})
+}
+
+// DisableMFAHandler disables two-factor authentication.
+func (s *UserServer) DisableMFAHandler(c *fiber.Ctx) error {
+	code := c.FormValue("code")
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.UserService.DisableMFA(c.Context(), payload, code)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusOK)
+}
+
+// FindAPIKeysHandler returns the api keys of the user.
+func (s *UserServer) FindAPIKeysHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	apiKeys, err := s.UserService.FindAPIKeys(c.Context(), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": apiKeys,
+	})
+}
+
+// CreateAPIKeyHandler creates an api key, the key is only returned this once.
+// scopes are separated by commas, expires_at is optional.
+func (s *UserServer) CreateAPIKeyHandler(c *fiber.Ctx) error {
+	name := c.FormValue("name")
+
+	scopes := []string{}
+	for _, scope := range strings.Split(c.FormValue("scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	var expiredAt *time.Time
+	if c.FormValue("expires_at") != "" {
+		t, err := time.Parse(time.RFC3339, c.FormValue("expires_at"))
+		if err != nil {
+			return helper.Error(c, helper.NewErr(helper.ErrParseCode, "expires_at"))
+		}
+		expiredAt = &t
+	}
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	apiKey, err := s.UserService.CreateAPIKey(c.Context(), payload, name, scopes, expiredAt)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusCreated).JSON(fiber.Map{
+		"data": apiKey,
+	})
+}
+
+// DeleteAPIKeyHandler deletes an api key of the user.
+func (s *UserServer) DeleteAPIKeyHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	err := s.UserService.DeleteAPIKey(c.Context(), c.Params("id"), payload)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// FindMyAuditEventsHandler returns the security events of the user page by page,
+// filtered by their action.
+func (s *UserServer) FindMyAuditEventsHandler(c *fiber.Ctx) error {
+	page := pagination.New(c.Query("page"), c.Query("limit"))
+
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	events, err := s.UserService.FindMyAuditEvents(c.Context(), payload, c.Query("action"), page.Page, page.Limit)
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": events,
+	})
+}
+
+// ExportDataHandler returns everything held about the user as a zip of json files.
+func (s *UserServer) ExportDataHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	data, err := s.UserService.ExportData(c.Context(), payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	c.Attachment("data-export.zip")
+	return c.Status(http.StatusOK).Send(data)
+}
+
+// ScheduleAccountDeletionHandler handles the deletion of the user's account.
+// if code in query is empty, it will send a code to the user's email
+// to confirm the deletion. if code in query is not empty,
+// it will schedule the deletion at the end of the grace period.
+func (s *UserServer) ScheduleAccountDeletionHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	code := c.Query("code")
+	if code != "" {
+		account, err := s.UserService.ScheduleAccountDeletion(c.Context(), payload, code, Client(c))
+		if err != nil {
+			return helper.Error(c, err)
+		}
+		return c.Status(http.StatusOK).JSON(fiber.Map{
+			"data": account,
+		})
+	}
+
+	err := s.UserService.SendVerificationCode(c.Context(), payload.Email, "delete-account", Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+	return c.SendStatus(http.StatusOK)
+}
+
+// CancelAccountDeletionHandler keeps the user's account during the grace period.
+func (s *UserServer) CancelAccountDeletionHandler(c *fiber.Ctx) error {
+	// get payload from context.
+	payload := AuthorizationPayload(c)
+
+	account, err := s.UserService.CancelAccountDeletion(c.Context(), payload, Client(c))
+	if err != nil {
+		return helper.Error(c, err)
+	}
+
+	return c.Status(http.StatusOK).JSON(fiber.Map{
+		"data": account,
+	})
+}
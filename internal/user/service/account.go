@@ -0,0 +1,231 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/common/pagination"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+	"time"
+)
+
+// ExportData returns everything held about the user as a zip of json files,
+// their profile, posts, comments, favorites and audit events.
+func (s *UserServiceImpl) ExportData(ctx context.Context, payload *token.Payload, client storage.Client) ([]byte, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	posts := <-s.ExportQuery.FindPosts(ctx, user.ID)
+	if posts.Error != nil {
+		return nil, posts.Error
+	}
+
+	comments := <-s.ExportQuery.FindComments(ctx, user.ID)
+	if comments.Error != nil {
+		return nil, comments.Error
+	}
+
+	favorites := <-s.ExportQuery.FindFavorites(ctx, user.ID)
+	if favorites.Error != nil {
+		return nil, favorites.Error
+	}
+
+	events, err := s.findAllAuditEvents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := bytes.Buffer{}
+	archive := zip.NewWriter(&buffer)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", toAccount(user)},
+		{"posts.json", posts.Result},
+		{"comments.json", comments.Result},
+		{"favorites.json", favorites.Result},
+		{"audit_events.json", events},
+	}
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		_, err = writer.Write(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err = archive.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.audit(ctx, user.ID, entity.AuditDataExported, user.ID, client, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// findAllAuditEvents returns every event the user is the actor or the target of,
+// as the user sees them, see redactAuditEvents.
+func (s *UserServiceImpl) findAllAuditEvents(ctx context.Context, userID string) ([]storage.AuditEvent, error) {
+	events := []storage.AuditEvent{}
+	for page := 1; ; page++ {
+		list, err := s.findAuditEvents(ctx, userID, "", page, pagination.MaxLimit)
+		if err != nil {
+			return nil, err
+		}
+
+		redactAuditEvents(list.Events, userID)
+		events = append(events, list.Events...)
+		if len(list.Events) < pagination.MaxLimit || len(events) >= list.Total {
+			return events, nil
+		}
+	}
+}
+
+// ScheduleAccountDeletion deletes the account of the user once the grace period is over,
+// the user re-authenticates with the code sent to their email.
+// the user can still log in and cancel the deletion until then.
+func (s *UserServiceImpl) ScheduleAccountDeletion(ctx context.Context, payload *token.Payload, code string, client storage.Client) (storage.Account, error) {
+	if len(code) != 10 {
+		return storage.Account{}, NewErr(ErrInvalidCode, "code")
+	}
+	if !NumberRegex.MatchString(code) {
+		return storage.Account{}, NewErr(ErrInvalidCode, "code")
+	}
+
+	err := s.verifyCode(payload.Email, code, "delete-account")
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.Account{}, err
+	}
+	if user.IsDeletionScheduled() {
+		return toAccount(user), nil
+	}
+
+	user.ScheduleDeletion(time.Now().Add(config.Env.AccountDeletionGracePeriod))
+	err = <-s.UserCommand.UpdateDeletion(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, user.ID, entity.AuditDeletionScheduled, user.ID, client, map[string]string{
+		"scheduled_at": user.DeletionScheduledAt.Format(time.RFC3339),
+	})
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// CancelAccountDeletion keeps the account of the user whose deletion has been scheduled.
+func (s *UserServiceImpl) CancelAccountDeletion(ctx context.Context, payload *token.Payload, client storage.Client) (storage.Account, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.Account{}, err
+	}
+	if !user.IsDeletionScheduled() {
+		return toAccount(user), nil
+	}
+
+	user.CancelDeletion()
+	err = <-s.UserCommand.UpdateDeletion(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, user.ID, entity.AuditDeletionCancelled, user.ID, client, nil)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// DeleteDueAccounts deletes the accounts whose grace period is over at the time,
+// and returns how many have been deleted. an account that can not be deleted
+// does not hold back the others, it is tried again on the next call.
+func (s *UserServiceImpl) DeleteDueAccounts(ctx context.Context, at time.Time) (int, error) {
+	result := <-s.UserQuery.FindDueForDeletion(ctx, at)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	users, ok := result.Result.([]storage.User)
+	if !ok {
+		return 0, errors.New("helper type assertion")
+	}
+
+	deleted := 0
+	var firstErr error
+	for _, stored := range users {
+		user, err := toUserEntity(query.Result{Result: stored})
+		if err != nil {
+			return deleted, err
+		}
+
+		// the user has asked for the deletion, the scheduler carries it out.
+		err = s.deleteAccount(ctx, user, user.ID, storage.Client{UserAgent: "scheduler"}, map[string]string{
+			"email":  user.Email,
+			"reason": "requested",
+		})
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, firstErr
+}
+
+// deleteAccount deletes the user along with their posts, favorites and
+// uploaded images, empties their comments, and logs them out everywhere.
+func (s *UserServiceImpl) deleteAccount(ctx context.Context, user entity.User, actorID string, client storage.Client, metadata map[string]string) error {
+	// the images are deleted first, the user is kept to try again when they can not be.
+	err := <-DeleteImages(ctx, s.CloudStorage, user.Email)
+	if err != nil {
+		return err
+	}
+
+	// the access tokens are revoked through the refresh tokens,
+	// which are deleted along with the user.
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	err = <-s.UserCommand.Delete(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// the email is kept, the id does not tell who the user was anymore.
+	return s.audit(ctx, actorID, entity.AuditUserDeleted, user.ID, client, metadata)
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxSuspensionReasonLength is how long the reason of a suspension kept in the audit trail can be.
+const maxSuspensionReasonLength = 500
+
+// FindAccounts returns a page of the users whose email, name or nickname
+// contains the search, with the role, empty filters match every user.
+func (s *UserServiceImpl) FindAccounts(ctx context.Context, search, role string, page, limit int) (storage.AccountList, error) {
+	if role != "" && !rbac.IsRole(role) {
+		return storage.AccountList{}, NewErr(ErrInvalidRoleCode, "role")
+	}
+
+	result := <-s.UserQuery.FindAll(ctx, strings.TrimSpace(search), role, limit, (page-1)*limit)
+	if result.Error != nil {
+		return storage.AccountList{}, result.Error
+	}
+
+	list, ok := result.Result.(storage.AccountList)
+	if !ok {
+		return storage.AccountList{}, errors.New("helper type assertion")
+	}
+
+	list.Page = page
+	list.Limit = limit
+	return list, nil
+}
+
+// FindAccount returns a user by id.
+func (s *UserServiceImpl) FindAccount(ctx context.Context, id string) (storage.Account, error) {
+	user, err := s.findUserByID(ctx, id)
+	if errors.Is(err, query.ErrAccountNotFound) {
+		return storage.Account{}, NewErr(ErrNotFoundCode, "user")
+	}
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// SuspendUser prevents the user from logging in, and logs them out everywhere.
+// their api keys stop working until they are unsuspended.
+func (s *UserServiceImpl) SuspendUser(ctx context.Context, id, reason string, payload *token.Payload, client storage.Client) (storage.Account, error) {
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > maxSuspensionReasonLength {
+		return storage.Account{}, NewErr(ErrFieldTooLongCode, "reason")
+	}
+
+	actor, user, err := s.findManagedUser(ctx, id, payload)
+	if err != nil {
+		return storage.Account{}, err
+	}
+	if user.IsSuspended() {
+		return toAccount(user), nil
+	}
+
+	user.Suspend(time.Now())
+	err = <-s.UserCommand.UpdateSuspension(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, actor.ID, entity.AuditUserSuspended, user.ID, client, map[string]string{"reason": reason})
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// UnsuspendUser allows the user to log in again.
+func (s *UserServiceImpl) UnsuspendUser(ctx context.Context, id string, payload *token.Payload, client storage.Client) (storage.Account, error) {
+	actor, user, err := s.findManagedUser(ctx, id, payload)
+	if err != nil {
+		return storage.Account{}, err
+	}
+	if !user.IsSuspended() {
+		return toAccount(user), nil
+	}
+
+	user.Unsuspend()
+	err = <-s.UserCommand.UpdateSuspension(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, actor.ID, entity.AuditUserUnsuspended, user.ID, client, nil)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// ForcePasswordReset prevents the user from logging in with their password
+// until they reset it, and logs them out everywhere. a reset code is sent to them.
+func (s *UserServiceImpl) ForcePasswordReset(ctx context.Context, id string, payload *token.Payload, client storage.Client) (storage.Account, error) {
+	actor, user, err := s.findManagedUser(ctx, id, payload)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	// the code is sent first, so that nothing changes when it can not be.
+	// the send limits are meant for the anonymous requests, not for the admins.
+	err = s.sendCode(ctx, user.Email, "reset-password")
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	user.RequirePasswordReset()
+	err = <-s.UserCommand.UpdatePassword(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, actor.ID, entity.AuditPasswordResetForced, user.ID, client, nil)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// ChangeRole changes the role of the user, and logs them out everywhere,
+// so that the tokens with the previous role can not be used anymore.
+func (s *UserServiceImpl) ChangeRole(ctx context.Context, id, role string, payload *token.Payload, client storage.Client) (storage.Account, error) {
+	if !rbac.IsRole(role) {
+		return storage.Account{}, NewErr(ErrInvalidRoleCode, "role")
+	}
+
+	actor, user, err := s.findManagedUser(ctx, id, payload)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	previous := user.Role
+	if previous == rbac.Role(role) {
+		return toAccount(user), nil
+	}
+
+	user.ChangeRole(rbac.Role(role))
+	err = <-s.UserCommand.UpdateRole(ctx, &user)
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	err = s.audit(ctx, actor.ID, entity.AuditRoleChanged, user.ID, client, map[string]string{
+		"from": string(previous),
+		"to":   role,
+	})
+	if err != nil {
+		return storage.Account{}, err
+	}
+
+	return toAccount(user), nil
+}
+
+// DeleteUser deletes the user along with their posts, comments, favorites and images.
+func (s *UserServiceImpl) DeleteUser(ctx context.Context, id string, payload *token.Payload, client storage.Client) error {
+	actor, user, err := s.findManagedUser(ctx, id, payload)
+	if err != nil {
+		return err
+	}
+
+	return s.deleteAccount(ctx, user, actor.ID, client, map[string]string{"email": user.Email})
+}
+
+// findManagedUser returns the admin of the payload and the user with the id,
+// admins do not manage themselves, so that they can not lock themselves out.
+func (s *UserServiceImpl) findManagedUser(ctx context.Context, id string, payload *token.Payload) (entity.User, entity.User, error) {
+	actor, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return entity.User{}, entity.User{}, err
+	}
+
+	user, err := s.findUserByID(ctx, id)
+	if errors.Is(err, query.ErrAccountNotFound) {
+		return entity.User{}, entity.User{}, NewErr(ErrNotFoundCode, "user")
+	}
+	if err != nil {
+		return entity.User{}, entity.User{}, err
+	}
+
+	if user.ID == actor.ID {
+		return entity.User{}, entity.User{}, NewErr(ErrForbiddenCode, "user")
+	}
+
+	return actor, user, nil
+}
+
+func toAccount(user entity.User) storage.Account {
+	return storage.Account{
+		ID:                    user.ID,
+		Name:                  user.Name,
+		Nickname:              user.Nickname,
+		Email:                 user.Email,
+		Bio:                   user.Bio,
+		Image:                 user.Image,
+		Role:                  user.Role,
+		MFAEnabled:            user.IsMFAEnabled(),
+		SuspendedAt:           user.SuspendedAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		DeletionScheduledAt:   user.DeletionScheduledAt,
+		CreatedDate:           user.CreatedDate,
+	}
+}
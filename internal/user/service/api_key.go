@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxAPIKeyNameLength is the length of the name column of the api keys.
+const maxAPIKeyNameLength = 100
+
+// CreateAPIKey creates a new api key of the user, limited to the scopes.
+// the key is only returned this once, a nil expiredAt never expires.
+func (s *UserServiceImpl) CreateAPIKey(ctx context.Context, payload *token.Payload, name string, scopes []string, expiredAt *time.Time) (storage.NewAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return storage.NewAPIKey{}, NewErr(ErrNameEmptyCode, "name")
+	}
+	if utf8.RuneCountInString(name) > maxAPIKeyNameLength {
+		return storage.NewAPIKey{}, NewErr(ErrFieldTooLongCode, "name")
+	}
+	if len(scopes) == 0 {
+		return storage.NewAPIKey{}, NewErr(ErrInvalidScopeCode, "scopes")
+	}
+	for _, scope := range scopes {
+		if !token.IsScope(scope) {
+			return storage.NewAPIKey{}, NewErr(ErrInvalidScopeCode, "scopes")
+		}
+	}
+	if expiredAt != nil && !expiredAt.After(time.Now()) {
+		return storage.NewAPIKey{}, NewErr(ErrExpiryInPastCode, "expires_at")
+	}
+
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.NewAPIKey{}, err
+	}
+
+	key, prefix, hash, err := token.GenerateAPIKey()
+	if err != nil {
+		return storage.NewAPIKey{}, err
+	}
+
+	apiKey := entity.CreateAPIKey(user.ID, name, prefix, hash, scopes, expiredAt)
+	err = <-s.APIKeyCommand.Save(ctx, apiKey)
+	if err != nil {
+		return storage.NewAPIKey{}, err
+	}
+
+	return storage.NewAPIKey{
+		APIKey: storage.APIKey{
+			ID:          apiKey.ID,
+			UserID:      apiKey.UserID,
+			Email:       user.Email,
+			Name:        apiKey.Name,
+			Prefix:      apiKey.Prefix,
+			Scopes:      apiKey.Scopes,
+			ExpiredAt:   apiKey.ExpiredAt,
+			CreatedDate: apiKey.CreatedDate,
+		},
+		Key: key,
+	}, nil
+}
+
+// FindAPIKeys returns the api keys of the user, the most recent first.
+func (s *UserServiceImpl) FindAPIKeys(ctx context.Context, payload *token.Payload) ([]storage.APIKey, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-s.APIKeyQuery.FindByUser(ctx, user.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	apiKeys, ok := result.Result.([]storage.APIKey)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	return apiKeys, nil
+}
+
+// DeleteAPIKey deletes an api key of the user, it can not be used anymore.
+func (s *UserServiceImpl) DeleteAPIKey(ctx context.Context, id string, payload *token.Payload) error {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return err
+	}
+
+	result := <-s.APIKeyQuery.FindByID(ctx, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrAPIKeyNotFound) {
+			return NewErr(ErrNotFoundCode, "api key")
+		}
+		return result.Error
+	}
+
+	apiKey, ok := result.Result.(storage.APIKey)
+	if !ok {
+		return errors.New("helper type assertion")
+	}
+
+	// the api keys of the other users are not disclosed.
+	if apiKey.UserID != user.ID {
+		return NewErr(ErrNotFoundCode, "api key")
+	}
+
+	return <-s.APIKeyCommand.Delete(ctx, apiKey.ID)
+}
+
+// VerifyAPIKey returns the payload of the api key, in the same shape as the
+// payload of a token, so that the handlers do not tell them apart.
+func (s *UserServiceImpl) VerifyAPIKey(ctx context.Context, key string) (*token.Payload, error) {
+	result := <-s.APIKeyQuery.FindByHash(ctx, token.HashAPIKey(key))
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrAPIKeyNotFound) {
+			return nil, NewErr(ErrAuthorizationInvalidTokenCode, "authorization_token")
+		}
+		return nil, result.Error
+	}
+
+	apiKey, ok := result.Result.(storage.APIKey)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	expiredAt := time.Time{}
+	if apiKey.ExpiredAt != nil {
+		if time.Now().After(*apiKey.ExpiredAt) {
+			return nil, NewErr(ErrAuthorizationInvalidTokenCode, "authorization_token")
+		}
+		expiredAt = *apiKey.ExpiredAt
+	}
+
+	scopes := make([]token.Scope, len(apiKey.Scopes))
+	for i, scope := range apiKey.Scopes {
+		scopes[i] = token.Scope(scope)
+	}
+
+	return token.NewAPIKeyPayload(apiKey.ID, apiKey.Email, apiKey.Role, scopes, apiKey.CreatedDate, expiredAt), nil
+}
+
+// SeeAPIKey records the use of the api key.
+func (s *UserServiceImpl) SeeAPIKey(ctx context.Context, id string) error {
+	return <-s.APIKeyCommand.See(ctx, id, time.Now())
+}
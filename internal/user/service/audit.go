@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+)
+
+// FindMyAuditEvents returns a page of the security events of the user,
+// the ones they have done and the ones that have been done to them,
+// see redactAuditEvents.
+func (s *UserServiceImpl) FindMyAuditEvents(ctx context.Context, payload *token.Payload, action string, page, limit int) (storage.AuditEventList, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.AuditEventList{}, err
+	}
+
+	list, err := s.findAuditEvents(ctx, user.ID, action, page, limit)
+	if err != nil {
+		return storage.AuditEventList{}, err
+	}
+
+	redactAuditEvents(list.Events, user.ID)
+	return list, nil
+}
+
+// FindAuditEvents returns a page of the whole audit trail, the user id
+// and the action filter it when they are not empty.
+func (s *UserServiceImpl) FindAuditEvents(ctx context.Context, userID, action string, page, limit int) (storage.AuditEventList, error) {
+	return s.findAuditEvents(ctx, userID, action, page, limit)
+}
+
+func (s *UserServiceImpl) findAuditEvents(ctx context.Context, userID, action string, page, limit int) (storage.AuditEventList, error) {
+	result := <-s.AuditEventQuery.FindAll(ctx, userID, action, limit, (page-1)*limit)
+	if result.Error != nil {
+		return storage.AuditEventList{}, result.Error
+	}
+
+	list, ok := result.Result.(storage.AuditEventList)
+	if !ok {
+		return storage.AuditEventList{}, errors.New("helper type assertion")
+	}
+
+	list.Page = page
+	list.Limit = limit
+	return list, nil
+}
+
+// redactAuditEvents blanks the email, the ip and the user agent of the actor
+// of the events the viewer has not done, such as an admin suspending them,
+// only the admins see the whole audit trail.
+func redactAuditEvents(events []storage.AuditEvent, viewerID string) {
+	for i := range events {
+		if events[i].ActorID == viewerID {
+			continue
+		}
+		events[i].ActorEmail = ""
+		events[i].IP = ""
+		events[i].UserAgent = ""
+	}
+}
+
+// audit appends an event to the audit trail.
+func (s *UserServiceImpl) audit(ctx context.Context, actorID string, action entity.AuditAction, targetID string, client storage.Client, metadata map[string]string) error {
+	event := entity.CreateAuditEvent(actorID, action, targetID, client.IP, client.UserAgent, metadata)
+	return <-s.AuditEventCommand.Save(ctx, event)
+}
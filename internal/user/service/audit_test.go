@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+	"testing"
+)
+
+// users is a UserQuery that only finds users by email.
+type users struct {
+	query.UserQuery
+	rows []storage.User
+}
+
+func (u users) FindByEmail(ctx context.Context, email string) <-chan query.Result {
+	result := make(chan query.Result, 1)
+	for _, user := range u.rows {
+		if user.Email == email {
+			result <- query.Result{Result: user}
+			return result
+		}
+	}
+	result <- query.Result{Error: query.ErrAccountNotFound}
+	return result
+}
+
+// auditEvents is an AuditEventQuery that returns the events of the actor or the target.
+type auditEvents []storage.AuditEvent
+
+func (a auditEvents) FindAll(ctx context.Context, userID, action string, limit, offset int) <-chan query.Result {
+	list := storage.AuditEventList{Events: []storage.AuditEvent{}}
+	for _, event := range a {
+		if userID == "" || event.ActorID == userID || event.TargetID == userID {
+			list.Events = append(list.Events, event)
+		}
+	}
+	list.Total = len(list.Events)
+
+	result := make(chan query.Result, 1)
+	result <- query.Result{Result: list}
+	return result
+}
+
+func TestFindMyAuditEventsRedactsOtherActors(t *testing.T) {
+	ctx := context.Background()
+	events := auditEvents{
+		{ID: "own", ActorID: "user", ActorEmail: testEmail, Action: "password_changed", TargetID: "user", IP: "10.0.0.1", UserAgent: "user agent"},
+		{ID: "admin", ActorID: "admin", ActorEmail: "admin@example.com", Action: "user_suspended", TargetID: "user", IP: "10.0.0.2", UserAgent: "admin agent"},
+		{ID: "anonymous", Action: "login_failed", TargetID: "user", IP: "10.0.0.3", UserAgent: "other agent"},
+	}
+	s := &UserServiceImpl{
+		UserQuery:       users{rows: []storage.User{{ID: "user", Email: testEmail}}},
+		AuditEventQuery: events,
+	}
+
+	list, err := s.FindMyAuditEvents(ctx, &token.Payload{Email: testEmail}, "", 1, 10)
+	if err != nil {
+		t.Fatalf("FindMyAuditEvents() error = %v", err)
+	}
+	if len(list.Events) != len(events) {
+		t.Fatalf("got %d events, want %d", len(list.Events), len(events))
+	}
+	for _, event := range list.Events {
+		redacted := event.ActorEmail == "" && event.IP == "" && event.UserAgent == ""
+		if redacted != (event.ID != "own") {
+			t.Errorf("event %s = %+v, want the actor redacted only when it is not the user", event.ID, event)
+		}
+	}
+
+	// the admins still see the whole events.
+	list, err = s.FindAuditEvents(ctx, "user", "", 1, 10)
+	if err != nil {
+		t.Fatalf("FindAuditEvents() error = %v", err)
+	}
+	for i, event := range list.Events {
+		want := events[i]
+		if event.ActorEmail != want.ActorEmail || event.IP != want.IP || event.UserAgent != want.UserAgent {
+			t.Errorf("admin event %s = %+v, want %+v", event.ID, event, want)
+		}
+	}
+}
+
+func TestFindAllAuditEventsRedactsOtherActors(t *testing.T) {
+	s := &UserServiceImpl{
+		AuditEventQuery: auditEvents{
+			{ID: "own", ActorID: "user", ActorEmail: testEmail, TargetID: "user", IP: "10.0.0.1", UserAgent: "user agent"},
+			{ID: "admin", ActorID: "admin", ActorEmail: "admin@example.com", TargetID: "user", IP: "10.0.0.2", UserAgent: "admin agent"},
+		},
+	}
+
+	// the events are those of the data export of the user.
+	events, err := s.findAllAuditEvents(context.Background(), "user")
+	if err != nil {
+		t.Fatalf("findAllAuditEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	if events[0].IP != "10.0.0.1" {
+		t.Errorf("own event = %+v, want it whole", events[0])
+	}
+	if events[1].ActorEmail != "" || events[1].IP != "" || events[1].UserAgent != "" {
+		t.Errorf("admin event = %+v, want the actor redacted", events[1])
+	}
+}
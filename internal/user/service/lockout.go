@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/memory"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"strings"
+	"time"
+)
+
+const (
+	// loginFailureWindow is how long a failed login of an account is remembered.
+	loginFailureWindow = 30 * time.Minute
+	// loginDelayAfter is how many failed logins of an account are allowed
+	// before each next attempt has to wait, twice as long every time.
+	loginDelayAfter = 3
+	// maxLoginDelay is the longest wait between two attempts.
+	maxLoginDelay = time.Minute
+	// maxLoginFailures is how many failed logins lock the account for accountLockDuration.
+	maxLoginFailures    = 10
+	accountLockDuration = 30 * time.Minute
+	// maxLoginFailuresPerIP is how many failed logins from an ip, whatever the
+	// account, block the ip until loginIPWindow is over.
+	maxLoginFailuresPerIP = 50
+	loginIPWindow         = time.Hour
+)
+
+// UnlockAccount unlocks the account locked after too many failed logins,
+// with the code that has been sent to the email.
+func (s *UserServiceImpl) UnlockAccount(ctx context.Context, email, code string, client storage.Client) error {
+	if len(code) != 10 {
+		return NewErr(ErrInvalidCode, "code")
+	}
+	if !NumberRegex.MatchString(code) {
+		return NewErr(ErrInvalidCode, "code")
+	}
+	if email == "" {
+		return NewErr(ErrEmailEmptyCode, "email")
+	}
+
+	err := s.verifyCode(email, code, "unlock")
+	if err != nil {
+		return err
+	}
+
+	err = s.unlock(email)
+	if err != nil {
+		return err
+	}
+
+	// a code can be asked for an email without an account, there is nobody to record it for.
+	user, err := s.FindUserByEmail(ctx, email)
+	if errors.Is(err, query.ErrAccountNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return s.audit(ctx, user.ID, entity.AuditAccountUnlocked, user.ID, client, nil)
+}
+
+// checkLogin refuses the login while the ip or the account is locked,
+// or while the account has to wait after its last failed login.
+func (s *UserServiceImpl) checkLogin(email string, client storage.Client) error {
+	wait, err := s.blockedFor(ipLockKey(client.IP))
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return NewRetryErr(ErrTooManyRequestsCode, "ip", wait)
+	}
+
+	wait, err = s.blockedFor(accountLockKey(email))
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return NewRetryErr(ErrAccountLockedCode, "email", wait)
+	}
+
+	wait, err = s.blockedFor(loginDelayKey(email))
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return NewRetryErr(ErrLoginDelayedCode, "email", wait)
+	}
+
+	return nil
+}
+
+// loginFailed counts a failed login against the account and the ip. it returns
+// the error to answer with when the account has been locked by this failure.
+func (s *UserServiceImpl) loginFailed(ctx context.Context, email string, accountExists bool, client storage.Client) error {
+	// the failure is recorded against the account, when there is one.
+	targetID := ""
+	if accountExists {
+		user, err := s.FindUserByEmail(ctx, email)
+		if err != nil {
+			return err
+		}
+		targetID = user.ID
+	}
+
+	err := s.audit(ctx, "", entity.AuditLoginFailed, targetID, client, map[string]string{"email": email})
+	if err != nil {
+		return err
+	}
+
+	ipFailures, err := s.increment(loginIPFailuresKey(client.IP), loginIPWindow)
+	if err != nil {
+		return err
+	}
+	if ipFailures.Count >= maxLoginFailuresPerIP {
+		err = s.block(ipLockKey(client.IP), ipFailures.ResetIn)
+		if err != nil {
+			return err
+		}
+	}
+
+	failures, err := s.increment(loginFailuresKey(email), loginFailureWindow)
+	if err != nil {
+		return err
+	}
+
+	if failures.Count >= maxLoginFailures {
+		err = s.block(accountLockKey(email), accountLockDuration)
+		if err != nil {
+			return err
+		}
+
+		err = <-s.TokenCommand.Delete(loginFailuresKey(email))
+		if err != nil {
+			return err
+		}
+
+		err = s.audit(ctx, "", entity.AuditAccountLocked, targetID, client, map[string]string{"email": email})
+		if err != nil {
+			return err
+		}
+
+		// the owner gets the unlock code right away, when it can not be sent
+		// they can still ask for a new one at /auth/unlock.
+		if accountExists {
+			_ = s.SendVerificationCode(ctx, email, "unlock", client)
+		}
+
+		return NewRetryErr(ErrAccountLockedCode, "email", accountLockDuration)
+	}
+
+	if failures.Count >= loginDelayAfter {
+		delay := time.Second << (failures.Count - loginDelayAfter)
+		if delay > maxLoginDelay {
+			delay = maxLoginDelay
+		}
+		return s.block(loginDelayKey(email), delay)
+	}
+
+	return nil
+}
+
+// unlock forgets the failed logins of the account and lifts its lock.
+func (s *UserServiceImpl) unlock(email string) error {
+	for _, key := range []string{loginFailuresKey(email), loginDelayKey(email), accountLockKey(email)} {
+		err := <-s.TokenCommand.Delete(key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isLoginFailure tells whether the error of FindByEmailAndPassword is a wrong email or password.
+func isLoginFailure(err error) bool {
+	return errors.Is(err, query.ErrAccountNotFound) || errors.Is(err, query.ErrIncorrectPassword)
+}
+
+// block blocks the key for d, the time it is blocked until is stored as its value.
+func (s *UserServiceImpl) block(key string, d time.Duration) error {
+	until := time.Now().Add(d).UTC().Format(time.RFC3339Nano)
+	return <-s.TokenCommand.Set(key, []byte(until), d)
+}
+
+// blockedFor returns how long the key is still blocked, zero when it is not.
+func (s *UserServiceImpl) blockedFor(key string) (time.Duration, error) {
+	result := <-s.TokenQuery.Find(key)
+	if errors.Is(result.Error, query.ErrTokenNotFound) {
+		return 0, nil
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	val, ok := result.Result.([]byte)
+	if !ok {
+		return 0, errors.New("helper type assertion")
+	}
+
+	until, err := time.Parse(time.RFC3339Nano, string(val))
+	if err != nil {
+		return 0, err
+	}
+
+	wait := time.Until(until)
+	if wait < 0 {
+		return 0, nil
+	}
+	return wait, nil
+}
+
+// increment adds one to the counter of the key.
+func (s *UserServiceImpl) increment(key string, window time.Duration) (memory.Counter, error) {
+	result := <-s.TokenCommand.Increment(key, window)
+	if result.Error != nil {
+		return memory.Counter{}, result.Error
+	}
+
+	counter, ok := result.Result.(memory.Counter)
+	if !ok {
+		return memory.Counter{}, errors.New("helper type assertion")
+	}
+	return counter, nil
+}
+
+func loginFailuresKey(email string) string {
+	return "login:failures:" + strings.ToLower(email)
+}
+
+func loginDelayKey(email string) string {
+	return "login:delay:" + strings.ToLower(email)
+}
+
+func accountLockKey(email string) string {
+	return "login:lock:" + strings.ToLower(email)
+}
+
+func loginIPFailuresKey(ip string) string {
+	return "login:failures:ip:" + ip
+}
+
+func ipLockKey(ip string) string {
+	return "login:lock:ip:" + ip
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/google/uuid"
+	"net/url"
+	"strings"
+)
+
+// loginLinkPath is the path of the sign-in link sent by email.
+const loginLinkPath = "/auth/login/link"
+
+// LoginWithLink signs in with the link sent to the email, see SendVerificationCode
+// with the "login" kind. the link can only be used once, before it expires.
+// the first sign-in of an email creates its account, without a password.
+func (s *UserServiceImpl) LoginWithLink(ctx context.Context, email, code string, client storage.Client) (storage.UserAuth, error) {
+	if len(code) != 10 {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
+	}
+	if !NumberRegex.MatchString(code) {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
+	}
+	if email == "" {
+		return storage.UserAuth{}, NewErr(ErrEmailEmptyCode, "email")
+	}
+
+	err := s.verifyCode(email, code, "login")
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	user, err := s.FindUserByEmail(ctx, email)
+	if errors.Is(err, query.ErrAccountNotFound) {
+		user, err = s.createPasswordlessUser(ctx, email, "")
+	}
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if user.IsSuspended() {
+		return storage.UserAuth{}, NewErr(ErrAccountSuspendedCode, "email")
+	}
+
+	// the email has been proven, guesses at the password do not matter anymore.
+	err = s.unlock(email)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	userAuth := storage.UserAuth{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}
+
+	// the link replaces the password, not the totp code.
+	if user.IsMFAEnabled() {
+		return s.startMFA(userAuth)
+	}
+
+	return s.startSession(ctx, userAuth, client, "link")
+}
+
+// createPasswordlessUser creates the account of a reader who signs in with links
+// or with a provider, it gets a random password which can be replaced with a
+// password reset. the name defaults to the local part of the email.
+func (s *UserServiceImpl) createPasswordlessUser(ctx context.Context, email, name string) (entity.User, error) {
+	if name == "" {
+		name = strings.Split(email, "@")[0]
+	}
+	user, err := entity.CreateUser(email, name, uuid.NewString())
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	err = s.saveNewUser(ctx, user)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return *user, nil
+}
+
+// loginLink returns the sign-in link of the code sent to the email.
+func loginLink(email, code string) string {
+	params := url.Values{}
+	params.Set("email", email)
+	params.Set("code", code)
+
+	return strings.TrimSuffix(config.Env.SiteURL, "/") + loginLinkPath + "?" + params.Encode()
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/common/totp"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/repository"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
+	"strings"
+	"time"
+)
+
+const (
+	// mfaPendingDuration is how long the totp code can be given after the password.
+	mfaPendingDuration = 5 * time.Minute
+	// maxMFAAttempts is how many codes can be tried with one mfa pending token.
+	maxMFAAttempts = 5
+	// recoveryCodeCount is how many recovery codes are given when enabling two-factor authentication.
+	recoveryCodeCount = 10
+)
+
+// EnrollMFA starts enabling two-factor authentication, the returned secret
+// is added to an authenticator app, then confirmed with ConfirmMFA.
+// enrolling again replaces a secret that has not been confirmed yet.
+func (s *UserServiceImpl) EnrollMFA(ctx context.Context, payload *token.Payload) (storage.MFAEnrollment, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.MFAEnrollment{}, err
+	}
+	if user.IsMFAEnabled() {
+		return storage.MFAEnrollment{}, NewErr(ErrMFAAlreadyEnabledCode, "mfa")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return storage.MFAEnrollment{}, err
+	}
+
+	// the secret is bound to the user, it can not be copied to another row.
+	encrypted, err := s.SecretCipher.Encrypt([]byte(secret), []byte(user.ID))
+	if err != nil {
+		return storage.MFAEnrollment{}, err
+	}
+
+	user.EnrollMFA(encrypted)
+	err = <-s.UserCommand.UpdateMFA(ctx, &user)
+	if err != nil {
+		return storage.MFAEnrollment{}, err
+	}
+
+	return storage.MFAEnrollment{
+		Secret: secret,
+		URI:    totp.URI(config.Env.SiteTitle, user.Email, secret),
+	}, nil
+}
+
+// ConfirmMFA enables two-factor authentication with a code of the enrolled
+// secret. it returns the recovery codes, which are only shown this once.
+func (s *UserServiceImpl) ConfirmMFA(ctx context.Context, payload *token.Payload, code string) ([]string, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsMFAEnabled() {
+		return nil, NewErr(ErrMFAAlreadyEnabledCode, "mfa")
+	}
+	if user.MFASecret == nil {
+		return nil, NewErr(ErrMFANotEnabledCode, "mfa")
+	}
+
+	err = s.checkTOTP(ctx, user, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = <-s.RecoveryCodeCommand.Replace(ctx, user.ID, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ConfirmMFA(time.Now())
+	err = <-s.UserCommand.UpdateMFA(ctx, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableMFA disables two-factor authentication with a totp or a recovery code.
+func (s *UserServiceImpl) DisableMFA(ctx context.Context, payload *token.Payload, code string) error {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return err
+	}
+	if !user.IsMFAEnabled() {
+		return NewErr(ErrMFANotEnabledCode, "mfa")
+	}
+
+	err = s.checkMFACode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	user.DisableMFA()
+	err = <-s.UserCommand.UpdateMFA(ctx, &user)
+	if err != nil {
+		return err
+	}
+
+	return <-s.RecoveryCodeCommand.Replace(ctx, user.ID, nil)
+}
+
+// VerifyMFA is the second step of Authorize for the users with two-factor
+// authentication, it exchanges the mfa pending token and a totp or a recovery
+// code for the tokens. the mfa pending token can only be exchanged once.
+func (s *UserServiceImpl) VerifyMFA(ctx context.Context, mfaToken, code string, client storage.Client) (storage.UserAuth, error) {
+	payload, err := s.TokenMaker.VerifyMFAPendingToken(mfaToken)
+	if err != nil {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "mfa_token")
+	}
+
+	revoked, err := s.IsTokenRevoked(ctx, payload.ID)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if revoked {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "mfa_token")
+	}
+
+	attempts, err := s.increment("mfa:attempts:"+payload.ID, mfaPendingDuration)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if attempts.Count > maxMFAAttempts {
+		err = <-s.RevokedTokenCommand.Revoke(ctx, payload.ID, payload.ExpiredAt)
+		if err != nil {
+			return storage.UserAuth{}, err
+		}
+		return storage.UserAuth{}, NewErr(ErrCodeAttemptsExceededCode, "code")
+	}
+
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if !user.IsMFAEnabled() {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "mfa_token")
+	}
+	if user.IsSuspended() {
+		return storage.UserAuth{}, NewErr(ErrAccountSuspendedCode, "mfa_token")
+	}
+
+	err = s.checkMFACode(ctx, user, code)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	err = <-s.RevokedTokenCommand.Revoke(ctx, payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	userAuth := storage.UserAuth{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}
+	return s.startSession(ctx, userAuth, client, "mfa")
+}
+
+// startMFA answers Authorize with an mfa pending token instead of the tokens.
+func (s *UserServiceImpl) startMFA(userAuth storage.UserAuth) (storage.UserAuth, error) {
+	mfaToken, _, err := s.TokenMaker.CreateMFAPendingToken(userAuth.Email, mfaPendingDuration)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	userAuth.MFAToken = mfaToken
+	return userAuth, nil
+}
+
+// checkMFACode accepts a totp code, or an unused recovery code which is then used up.
+func (s *UserServiceImpl) checkMFACode(ctx context.Context, user entity.User, code string) error {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) == totp.Digits && NumberRegex.MatchString(code) {
+		return s.checkTOTP(ctx, user, code)
+	}
+	if code == "" {
+		return NewErr(ErrInvalidCode, "code")
+	}
+
+	err := <-s.RecoveryCodeCommand.Use(ctx, user.ID, hashRecoveryCode(code), time.Now())
+	if errors.Is(err, repository.ErrRecoveryCodeNotFound) {
+		return NewErr(ErrInvalidCode, "code")
+	}
+	return err
+}
+
+// checkTOTP accepts a totp code of the user's secret, each code only once.
+func (s *UserServiceImpl) checkTOTP(ctx context.Context, user entity.User, code string) error {
+	secret, err := s.SecretCipher.Decrypt(user.MFASecret, []byte(user.ID))
+	if err != nil {
+		return err
+	}
+
+	step, ok, err := totp.Validate(string(secret), strings.TrimSpace(code), time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NewErr(ErrInvalidCode, "code")
+	}
+
+	err = <-s.UserCommand.UseMFAStep(ctx, user.ID, step)
+	if errors.Is(err, repository.ErrMFAStepUsed) {
+		return NewErr(ErrInvalidCode, "code")
+	}
+	return err
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted as xxxxx-xxxxx,
+// and their hashes to be stored.
+func generateRecoveryCodes() ([]string, [][]byte, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 5)
+		_, err := rand.Read(b)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := hex.EncodeToString(b)
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes the recovery code, the dash and the case do not matter.
+// the codes are random enough for a fast hash.
+func hashRecoveryCode(code string) []byte {
+	code = strings.ToLower(strings.ReplaceAll(code, "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return sum[:]
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SemmiDev/blog/internal/common/oidc"
+	"github.com/SemmiDev/blog/internal/user/entity"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	"github.com/SemmiDev/blog/internal/user/query"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"strings"
+	"time"
+)
+
+const (
+	// oidcStateDuration is how long the user has to sign in at the provider.
+	oidcStateDuration = 10 * time.Minute
+	// maxNameLength is the length of the name column of the users.
+	maxNameLength = 50
+)
+
+// StartOIDC returns the url of the provider to sign in at. the state, the nonce
+// and the PKCE verifier of the attempt are kept until the user comes back.
+func (s *UserServiceImpl) StartOIDC(ctx context.Context, providerName string) (string, error) {
+	provider, ok := s.OIDCProviders[providerName]
+	if !ok {
+		return "", NewErr(ErrNotFoundCode, "provider")
+	}
+
+	state, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	nonce, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+	verifier, err := oidc.RandomString()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.Challenge(verifier))
+	if err != nil {
+		return "", err
+	}
+
+	val := fmt.Sprintf("%s|%s|%s", provider.Name, nonce, verifier)
+	err = <-s.TokenCommand.Set(oidcStateKey(state), []byte(val), oidcStateDuration)
+	if err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// LoginWithOIDC finishes signing in at the provider, the state can only be used once.
+// the subject of the provider is linked to the user with the same verified email,
+// or to a new user, the first time they sign in.
+func (s *UserServiceImpl) LoginWithOIDC(ctx context.Context, providerName, state, code string, client storage.Client) (storage.UserAuth, error) {
+	provider, ok := s.OIDCProviders[providerName]
+	if !ok {
+		return storage.UserAuth{}, NewErr(ErrNotFoundCode, "provider")
+	}
+	if state == "" {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "state")
+	}
+	if code == "" {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
+	}
+
+	stateResult := <-s.TokenQuery.Find(oidcStateKey(state))
+	if stateResult.Error != nil {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "state")
+	}
+
+	val, _ := stateResult.Result.([]byte)
+	err := <-s.TokenCommand.Delete(oidcStateKey(state))
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	extract := strings.Split(string(val), "|")
+	if len(extract) != 3 || extract[0] != provider.Name {
+		return storage.UserAuth{}, NewErr(ErrInvalidCode, "state")
+	}
+
+	claims, err := provider.Exchange(ctx, code, extract[2], extract[1])
+	if errors.Is(err, oidc.ErrInvalidIDToken) {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "id_token")
+	}
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	user, err := s.findOrLinkIdentity(ctx, provider.Name, claims)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if user.IsSuspended() {
+		return storage.UserAuth{}, NewErr(ErrAccountSuspendedCode, "email")
+	}
+
+	userAuth := storage.UserAuth{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}
+
+	// the provider replaces the password, not the totp code.
+	if user.IsMFAEnabled() {
+		return s.startMFA(userAuth)
+	}
+
+	return s.startSession(ctx, userAuth, client, "oidc:"+provider.Name)
+}
+
+// findOrLinkIdentity returns the user the subject is linked to,
+// it links the subject when it signs in for the first time.
+func (s *UserServiceImpl) findOrLinkIdentity(ctx context.Context, providerName string, claims *oidc.Claims) (entity.User, error) {
+	identityResult := <-s.IdentityQuery.FindBySubject(ctx, providerName, claims.Subject)
+	if identityResult.Error == nil {
+		identity, ok := identityResult.Result.(storage.Identity)
+		if !ok {
+			return entity.User{}, errors.New("helper type assertion")
+		}
+		return s.FindUserByEmail(ctx, identity.Email)
+	}
+	if !errors.Is(identityResult.Error, query.ErrIdentityNotFound) {
+		return entity.User{}, identityResult.Error
+	}
+
+	// an unverified email could belong to someone else's account.
+	email := strings.ToLower(claims.Email)
+	if email == "" || !claims.EmailVerified {
+		return entity.User{}, NewErr(ErrEmailNotVerifiedCode, "email")
+	}
+	if !MailRegex.MatchString(email) {
+		return entity.User{}, NewErr(ErrInvalidEmailCode, "email")
+	}
+
+	user, err := s.FindUserByEmail(ctx, email)
+	if errors.Is(err, query.ErrAccountNotFound) {
+		user, err = s.createPasswordlessUser(ctx, email, truncateName(claims.Name))
+	}
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	err = <-s.IdentityCommand.Save(ctx, entity.CreateIdentity(providerName, claims.Subject, user.ID))
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+// truncateName cuts the name to the length of the name column.
+func truncateName(name string) string {
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > maxNameLength {
+		runes = runes[:maxNameLength]
+	}
+	return string(runes)
+}
+
+func oidcStateKey(state string) string {
+	return "oidc:state:" + state
+}
@@ -4,26 +4,74 @@ import (
 	"context"
 	"errors"
 	"github.com/SemmiDev/blog/internal/user/entity"
+	"github.com/SemmiDev/blog/internal/user/query"
 	"github.com/SemmiDev/blog/internal/user/storage"
+	"github.com/SemmiDev/blog/internal/user/token"
 	"mime/multipart"
+	"time"
 )
 
 // UserService is a service for managing users.
 type UserService interface {
 	FindUserByEmail(ctx context.Context, email string) (entity.User, error)
-	SendVerificationCode(ctx context.Context, email string, kind string) error
-	RegisterNewUser(ctx context.Context, code, name, password string) (storage.UserAuth, error)
-	Authorize(ctx context.Context, email, password string) (storage.UserAuth, error)
-	ResetPassword(ctx context.Context, code, newPassword, newConfirmPassword string) error
-	ChangePassword(ctx context.Context, oldPassword, newPassword, newConfirmPassword, email string) error
-	ChangeBio(ctx context.Context, bio, email string) error
-	ChangeImage(ctx context.Context, file *multipart.FileHeader, email string) error
+	SendVerificationCode(ctx context.Context, email string, kind string, client storage.Client) error
+	RegisterNewUser(ctx context.Context, email, code, name, password string, client storage.Client) (storage.UserAuth, error)
+	Authorize(ctx context.Context, email, password string, client storage.Client) (storage.UserAuth, error)
+	RefreshToken(ctx context.Context, refreshToken string, client storage.Client) (storage.UserAuth, error)
+	Logout(ctx context.Context, payload *token.Payload) error
+	LogoutEverywhere(ctx context.Context, payload *token.Payload) error
+	IsTokenRevoked(ctx context.Context, id string) (bool, error)
+	FindSessions(ctx context.Context, payload *token.Payload) ([]storage.Session, error)
+	RevokeSession(ctx context.Context, id string, payload *token.Payload) error
+	SeeSession(ctx context.Context, accessTokenID, ip string) error
+	ResetPassword(ctx context.Context, email, code, newPassword, newConfirmPassword string, client storage.Client) error
+	UnlockAccount(ctx context.Context, email, code string, client storage.Client) error
+	EnrollMFA(ctx context.Context, payload *token.Payload) (storage.MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, payload *token.Payload, code string) ([]string, error)
+	DisableMFA(ctx context.Context, payload *token.Payload, code string) error
+	VerifyMFA(ctx context.Context, mfaToken, code string, client storage.Client) (storage.UserAuth, error)
+	LoginWithLink(ctx context.Context, email, code string, client storage.Client) (storage.UserAuth, error)
+	StartOIDC(ctx context.Context, providerName string) (string, error)
+	LoginWithOIDC(ctx context.Context, providerName, state, code string, client storage.Client) (storage.UserAuth, error)
+	CreateAPIKey(ctx context.Context, payload *token.Payload, name string, scopes []string, expiredAt *time.Time) (storage.NewAPIKey, error)
+	FindAPIKeys(ctx context.Context, payload *token.Payload) ([]storage.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string, payload *token.Payload) error
+	VerifyAPIKey(ctx context.Context, key string) (*token.Payload, error)
+	SeeAPIKey(ctx context.Context, id string) error
+	ChangePassword(ctx context.Context, oldPassword, newPassword, newConfirmPassword, email string, client storage.Client) error
+	ChangeBio(ctx context.Context, bio, email string, client storage.Client) error
+	ChangeImage(ctx context.Context, file *multipart.FileHeader, email string, client storage.Client) error
+	FindMyAuditEvents(ctx context.Context, payload *token.Payload, action string, page, limit int) (storage.AuditEventList, error)
+	ExportData(ctx context.Context, payload *token.Payload, client storage.Client) ([]byte, error)
+	ScheduleAccountDeletion(ctx context.Context, payload *token.Payload, code string, client storage.Client) (storage.Account, error)
+	CancelAccountDeletion(ctx context.Context, payload *token.Payload, client storage.Client) (storage.Account, error)
+}
+
+// AdminService is a service for the admins to manage the other users,
+// every change is written to the audit trail.
+type AdminService interface {
+	FindAccounts(ctx context.Context, search, role string, page, limit int) (storage.AccountList, error)
+	FindAccount(ctx context.Context, id string) (storage.Account, error)
+	SuspendUser(ctx context.Context, id, reason string, payload *token.Payload, client storage.Client) (storage.Account, error)
+	UnsuspendUser(ctx context.Context, id string, payload *token.Payload, client storage.Client) (storage.Account, error)
+	ForcePasswordReset(ctx context.Context, id string, payload *token.Payload, client storage.Client) (storage.Account, error)
+	ChangeRole(ctx context.Context, id, role string, payload *token.Payload, client storage.Client) (storage.Account, error)
+	DeleteUser(ctx context.Context, id string, payload *token.Payload, client storage.Client) error
+	FindAuditEvents(ctx context.Context, userID, action string, page, limit int) (storage.AuditEventList, error)
 }
 
 // FindUserByEmail returns a user by email.
 func (s UserServiceImpl) FindUserByEmail(ctx context.Context, email string) (entity.User, error) {
-	result := <-s.UserQuery.FindByEmail(ctx, email)
+	return toUserEntity(<-s.UserQuery.FindByEmail(ctx, email))
+}
+
+// findUserByID returns a user by id.
+func (s UserServiceImpl) findUserByID(ctx context.Context, id string) (entity.User, error) {
+	return toUserEntity(<-s.UserQuery.FindByID(ctx, id))
+}
 
+// toUserEntity returns the user of the query result.
+func toUserEntity(result query.Result) (entity.User, error) {
 	if result.Error != nil {
 		return entity.User{}, result.Error
 	}
@@ -34,14 +82,21 @@ func (s UserServiceImpl) FindUserByEmail(ctx context.Context, email string) (ent
 	}
 
 	userResult := entity.User{
-		ID:          user.ID,
-		Name:        user.Name,
-		Nickname:    user.Nickname,
-		Email:       user.Email,
-		Password:    user.Password,
-		Bio:         user.Bio,
-		Image:       user.Image,
-		CreatedDate: user.CreatedDate,
+		ID:                    user.ID,
+		Name:                  user.Name,
+		Nickname:              user.Nickname,
+		Email:                 user.Email,
+		Password:              user.Password,
+		Bio:                   user.Bio,
+		Image:                 user.Image,
+		CreatedDate:           user.CreatedDate,
+		MFASecret:             user.MFASecret,
+		MFAEnabledAt:          user.MFAEnabledAt,
+		MFALastStep:           user.MFALastStep,
+		Role:                  user.Role,
+		SuspendedAt:           user.SuspendedAt,
+		PasswordResetRequired: user.PasswordResetRequired,
+		DeletionScheduledAt:   user.DeletionScheduledAt,
 	}
 
 	return userResult, nil
@@ -6,31 +6,78 @@ import (
 	"errors"
 	"fmt"
 	"github.com/SemmiDev/blog/config"
+	"github.com/SemmiDev/blog/internal/common/encryption"
+	"github.com/SemmiDev/blog/internal/common/mail"
+	"github.com/SemmiDev/blog/internal/common/markdown"
+	"github.com/SemmiDev/blog/internal/common/oidc"
 	"github.com/SemmiDev/blog/internal/common/random"
+	"github.com/SemmiDev/blog/internal/common/slug"
 	"github.com/SemmiDev/blog/internal/user/entity"
 	. "github.com/SemmiDev/blog/internal/user/helper"
 	"github.com/SemmiDev/blog/internal/user/query"
 	"github.com/SemmiDev/blog/internal/user/repository"
 	"github.com/SemmiDev/blog/internal/user/storage"
 	"github.com/SemmiDev/blog/internal/user/token"
-	"log"
 	"mime/multipart"
 	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+// verificationCodeDuration is how long a verification code can be used.
+const verificationCodeDuration = 30 * time.Minute
+
+const (
+	// maxCodeAttempts is how many wrong codes are accepted before the code is invalidated.
+	maxCodeAttempts = 5
+	// sendCooldown is how long to wait before sending another code to the same email.
+	sendCooldown = time.Minute
+	// maxSendsPerEmail and maxSendsPerIP are how many codes can be sent in sendWindow.
+	maxSendsPerEmail = 5
+	maxSendsPerIP    = 20
+	sendWindow       = time.Hour
+)
+
+const (
+	// maxNicknameLength leaves room for a suffix in the VARCHAR(50) nickname column.
+	maxNicknameLength = 44
+	// maxNicknameAttempts bounds the retries when a nickname is taken while saving.
+	maxNicknameAttempts = 3
 )
 
 // UserServiceImpl is a struct that implements UserService interface.
 type UserServiceImpl struct {
-	UserQuery    query.UserQuery
-	TokenQuery   query.TokenQuery
-	UserCommand  repository.UserCommand
-	TokenCommand repository.TokenCommand
-	TokenMaker   token.Maker
-	CloudStorage *cloud.Client
+	UserQuery           query.UserQuery
+	TokenQuery          query.TokenQuery
+	RefreshTokenQuery   query.RefreshTokenQuery
+	RevokedTokenQuery   query.RevokedTokenQuery
+	SessionQuery        query.SessionQuery
+	UserCommand         repository.UserCommand
+	TokenCommand        repository.TokenCommand
+	RefreshTokenCommand repository.RefreshTokenCommand
+	RevokedTokenCommand repository.RevokedTokenCommand
+	SessionCommand      repository.SessionCommand
+	RecoveryCodeCommand repository.RecoveryCodeCommand
+	IdentityQuery       query.IdentityQuery
+	IdentityCommand     repository.IdentityCommand
+	APIKeyQuery         query.APIKeyQuery
+	APIKeyCommand       repository.APIKeyCommand
+	AuditEventQuery     query.AuditEventQuery
+	AuditEventCommand   repository.AuditEventCommand
+	ExportQuery         query.ExportQuery
+	TokenMaker          token.Maker
+	CloudStorage        *cloud.Client
+	Mailer              mail.Mailer
+	// SecretCipher encrypts the totp secrets of two-factor authentication.
+	SecretCipher *encryption.Cipher
+	// OIDCProviders are the OpenID Connect providers users can sign in with, by name.
+	OIDCProviders map[string]*oidc.Provider
 }
 
 // SendVerificationCode sends verification code to user's email.
-func (s *UserServiceImpl) SendVerificationCode(ctx context.Context, email string, kind string) error {
+// the kind is the template of the email, "registration", "reset-password", "unlock", "login" or "delete-account".
+// sending is limited per email and per ip, a new code replaces the previous one.
+func (s *UserServiceImpl) SendVerificationCode(ctx context.Context, email string, kind string, client storage.Client) error {
 	if email == "" {
 		return NewErr(ErrEmailEmptyCode, "email")
 	}
@@ -38,27 +85,76 @@ func (s *UserServiceImpl) SendVerificationCode(ctx context.Context, email string
 		return NewErr(ErrInvalidEmailCode, "email")
 	}
 
+	err := s.limit("send:cooldown:"+email, 1, sendCooldown, "email")
+	if err != nil {
+		return err
+	}
+	err = s.limit("send:email:"+email, maxSendsPerEmail, sendWindow, "email")
+	if err != nil {
+		return err
+	}
+	err = s.limit("send:ip:"+client.IP, maxSendsPerIP, sendWindow, "ip")
+	if err != nil {
+		return err
+	}
+
+	return s.sendCode(ctx, email, kind)
+}
+
+// sendCode sends a new code of kind to the email without any limit,
+// the callers are either limited or trusted.
+func (s *UserServiceImpl) sendCode(ctx context.Context, email string, kind string) error {
 	key := random.Codes(10)
 	val := fmt.Sprintf("%s|%s|%s", key, email, kind)
 
-	// log the value for now
-	log.Println(val)
+	// the login email signs in with a link rather than with the code.
+	link := ""
+	if kind == "login" {
+		link = loginLink(email, key)
+	}
+
+	message, err := mail.Compose(kind, email, struct {
+		SiteTitle string
+		SiteURL   string
+		Code      string
+		Link      string
+		ExpiresIn string
+	}{
+		SiteTitle: config.Env.SiteTitle,
+		SiteURL:   config.Env.SiteURL,
+		Code:      key,
+		Link:      link,
+		ExpiresIn: fmt.Sprintf("%d minutes", int(verificationCodeDuration.Minutes())),
+	})
+	if err != nil {
+		return err
+	}
 
-	err := <-s.TokenCommand.Set(key, []byte(val), 30*time.Minute)
+	err = <-s.TokenCommand.Set(codeKey(kind, email), []byte(val), verificationCodeDuration)
 	if err != nil {
 		return err
 	}
-	return nil
+
+	// the new code starts without failed attempts.
+	err = <-s.TokenCommand.Delete(attemptsKey(kind, email))
+	if err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(ctx, message)
 }
 
 // RegisterNewUser registers new user.
-func (s *UserServiceImpl) RegisterNewUser(ctx context.Context, code, name, password string) (storage.UserAuth, error) {
+func (s *UserServiceImpl) RegisterNewUser(ctx context.Context, email, code, name, password string, client storage.Client) (storage.UserAuth, error) {
 	if len(code) != 10 {
 		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
 	}
 	if !NumberRegex.MatchString(code) {
 		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
 	}
+	if email == "" {
+		return storage.UserAuth{}, NewErr(ErrEmailEmptyCode, "email")
+	}
 	if name == "" {
 		return storage.UserAuth{}, NewErr(ErrNameEmptyCode, "name")
 	}
@@ -69,23 +165,7 @@ func (s *UserServiceImpl) RegisterNewUser(ctx context.Context, code, name, passw
 		return storage.UserAuth{}, NewErr(ErrInvalidPasswordLengthCode, "password")
 	}
 
-	tokenResult := <-s.TokenQuery.Find(code)
-	if tokenResult.Error != nil {
-		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
-	}
-
-	codeVerification, _ := tokenResult.Result.([]byte)
-	if codeVerification == nil {
-		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
-	}
-
-	extractCode := strings.Split(string(codeVerification), "|")
-	if extractCode[0] != code {
-		return storage.UserAuth{}, NewErr(ErrInvalidCode, "code")
-	}
-	email := extractCode[1]
-
-	err := <-s.TokenCommand.Delete(code)
+	err := s.verifyCode(email, code, "registration")
 	if err != nil {
 		return storage.UserAuth{}, err
 	}
@@ -95,29 +175,65 @@ func (s *UserServiceImpl) RegisterNewUser(ctx context.Context, code, name, passw
 		return storage.UserAuth{}, err
 	}
 
-	err = <-s.UserCommand.Save(ctx, user)
+	err = s.saveNewUser(ctx, user)
 	if err != nil {
 		return storage.UserAuth{}, err
 	}
 
-	accessToken, err := s.TokenMaker.CreateToken(user.Email, config.Env.AccessTokenDuration)
+	err = s.audit(ctx, user.ID, entity.AuditUserRegistered, user.ID, client, nil)
 	if err != nil {
 		return storage.UserAuth{}, err
 	}
 
 	auth := storage.UserAuth{
-		UserID:      user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		NickName:    user.Nickname,
-		AccessToken: accessToken,
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
 	}
 
-	return auth, nil
+	return s.startSession(ctx, auth, client, "registration")
+}
+
+// saveNewUser saves a new user with a nickname no one else has,
+// their nickname followed by -2, -3, ... when it is taken.
+func (s *UserServiceImpl) saveNewUser(ctx context.Context, user *entity.User) error {
+	base := user.Nickname
+	if utf8.RuneCountInString(base) > maxNicknameLength {
+		base = string([]rune(base)[:maxNicknameLength])
+	}
+
+	// another user may take the nickname between the check and the save.
+	for attempt := 0; attempt < maxNicknameAttempts; attempt++ {
+		nickname, err := slug.Unique(base, func(candidate string) (bool, error) {
+			result := <-s.UserQuery.NicknameExists(ctx, candidate)
+			if result.Error != nil {
+				return false, result.Error
+			}
+
+			exists, ok := result.Result.(bool)
+			if !ok {
+				return false, errors.New("helper type assertion")
+			}
+			return exists, nil
+		})
+		if err != nil {
+			return err
+		}
+
+		user.Nickname = nickname
+		err = <-s.UserCommand.Save(ctx, user)
+		if !errors.Is(err, repository.ErrNicknameTaken) {
+			return err
+		}
+	}
+
+	return repository.ErrNicknameTaken
 }
 
 // Authorize user by email and password.
-func (s *UserServiceImpl) Authorize(ctx context.Context, email string, password string) (storage.UserAuth, error) {
+func (s *UserServiceImpl) Authorize(ctx context.Context, email string, password string, client storage.Client) (storage.UserAuth, error) {
 	if email == "" {
 		return storage.UserAuth{}, NewErr(ErrEmailEmptyCode, "email")
 	}
@@ -131,7 +247,20 @@ func (s *UserServiceImpl) Authorize(ctx context.Context, email string, password
 		return storage.UserAuth{}, NewErr(ErrInvalidPasswordLengthCode, "password")
 	}
 
+	err := s.checkLogin(email, client)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
 	queryResult := <-s.UserQuery.FindByEmailAndPassword(ctx, email, password)
+	if isLoginFailure(queryResult.Error) {
+		accountExists := !errors.Is(queryResult.Error, query.ErrAccountNotFound)
+		err = s.loginFailed(ctx, email, accountExists, client)
+		if err != nil {
+			return storage.UserAuth{}, err
+		}
+		return storage.UserAuth{}, queryResult.Error
+	}
 	if queryResult.Error != nil {
 		return storage.UserAuth{}, queryResult.Error
 	}
@@ -141,25 +270,313 @@ func (s *UserServiceImpl) Authorize(ctx context.Context, email string, password
 		return storage.UserAuth{}, errors.New("helper type assertion")
 	}
 
-	accessToken, _ := s.TokenMaker.CreateToken(user.Email, config.Env.AccessTokenDuration)
+	err = s.unlock(email)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	// the password is only checked first, so that the state of
+	// the account is not disclosed to whoever guesses it.
+	if user.SuspendedAt != nil {
+		return storage.UserAuth{}, NewErr(ErrAccountSuspendedCode, "email")
+	}
+	if user.PasswordResetRequired {
+		return storage.UserAuth{}, NewErr(ErrPasswordResetRequiredCode, "password")
+	}
+
 	userAuth := storage.UserAuth{
-		UserID:      user.ID,
-		Name:        user.Name,
-		Email:       user.Email,
-		NickName:    user.Nickname,
-		AccessToken: accessToken,
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}
+
+	// the password is not enough, the totp code is given at /auth/mfa/verify.
+	if user.MFAEnabledAt != nil {
+		return s.startMFA(userAuth)
+	}
+
+	return s.startSession(ctx, userAuth, client, "password")
+}
+
+// RefreshToken exchanges a refresh token for a new pair of tokens.
+// a refresh token can be used once, using it again means it has been
+// stolen, so every refresh token of its login is revoked.
+func (s *UserServiceImpl) RefreshToken(ctx context.Context, refreshToken string, client storage.Client) (storage.UserAuth, error) {
+	payload, err := s.TokenMaker.VerifyRefreshToken(refreshToken)
+	if err != nil {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "refresh_token")
+	}
+
+	result := <-s.RefreshTokenQuery.FindByID(ctx, payload.ID)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrRefreshTokenNotFound) {
+			return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "refresh_token")
+		}
+		return storage.UserAuth{}, result.Error
+	}
+
+	stored, ok := result.Result.(storage.RefreshToken)
+	if !ok {
+		return storage.UserAuth{}, errors.New("helper type assertion")
+	}
+
+	if stored.RevokedAt != nil {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "refresh_token")
+	}
+
+	err = <-s.RefreshTokenCommand.Use(ctx, stored.ID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			err = <-s.RefreshTokenCommand.RevokeFamily(ctx, stored.FamilyID, time.Now())
+			if err != nil {
+				return storage.UserAuth{}, err
+			}
+			return storage.UserAuth{}, NewErr(ErrRefreshTokenReusedCode, "refresh_token")
+		}
+		return storage.UserAuth{}, err
+	}
+
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+	if user.ID != stored.UserID {
+		return storage.UserAuth{}, NewErr(ErrAuthorizationInvalidTokenCode, "refresh_token")
+	}
+	if user.IsSuspended() {
+		return storage.UserAuth{}, NewErr(ErrAccountSuspendedCode, "refresh_token")
+	}
+
+	userAuth := storage.UserAuth{
+		UserID:   user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		NickName: user.Nickname,
+		Role:     user.Role,
+	}
+	userAuth, err = s.issueTokens(ctx, userAuth, stored.FamilyID)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	sessionResult := <-s.SessionQuery.FindByID(ctx, stored.FamilyID)
+	if sessionResult.Error != nil {
+		// the login has been made before the sessions were recorded.
+		if errors.Is(sessionResult.Error, query.ErrSessionNotFound) {
+			return userAuth, nil
+		}
+		return storage.UserAuth{}, sessionResult.Error
+	}
+
+	session, ok := sessionResult.Result.(storage.Session)
+	if !ok {
+		return storage.UserAuth{}, errors.New("helper type assertion")
+	}
+
+	sessionEntity := toSessionEntity(session)
+	sessionEntity.Refresh(client.UserAgent, client.IP, time.Now().Add(config.Env.RefreshTokenDuration))
+	err = <-s.SessionCommand.Update(ctx, &sessionEntity)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	return userAuth, nil
+}
+
+// Logout revokes the access token of the payload, and the login it belongs to,
+// so that neither the token nor its refresh tokens can be used anymore.
+func (s *UserServiceImpl) Logout(ctx context.Context, payload *token.Payload) error {
+	err := <-s.RevokedTokenCommand.Revoke(ctx, payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return err
+	}
+
+	result := <-s.RefreshTokenQuery.FindByAccessTokenID(ctx, payload.ID)
+	if result.Error != nil {
+		// the token has been issued without a refresh token.
+		if errors.Is(result.Error, query.ErrRefreshTokenNotFound) {
+			return nil
+		}
+		return result.Error
+	}
+
+	stored, ok := result.Result.(storage.RefreshToken)
+	if !ok {
+		return errors.New("helper type assertion")
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeFamily(ctx, stored.FamilyID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LogoutEverywhere revokes the access token of the payload and every login of its user.
+func (s *UserServiceImpl) LogoutEverywhere(ctx context.Context, payload *token.Payload) error {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return err
+	}
+
+	err = <-s.RevokedTokenCommand.Revoke(ctx, payload.ID, payload.ExpiredAt)
+	if err != nil {
+		return err
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return err
 	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the access token has been revoked before it expires.
+func (s *UserServiceImpl) IsTokenRevoked(ctx context.Context, id string) (bool, error) {
+	result := <-s.RevokedTokenQuery.IsRevoked(ctx, id)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	revoked, ok := result.Result.(bool)
+	if !ok {
+		return false, errors.New("helper type assertion")
+	}
+
+	return revoked, nil
+}
+
+// FindSessions returns the active sessions of the user,
+// the session of the payload is marked as the current one.
+func (s *UserServiceImpl) FindSessions(ctx context.Context, payload *token.Payload) ([]storage.Session, error) {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-s.SessionQuery.FindActiveByUser(ctx, user.ID)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	sessions, ok := result.Result.([]storage.Session)
+	if !ok {
+		return nil, errors.New("helper type assertion")
+	}
+
+	currentResult := <-s.RefreshTokenQuery.FindByAccessTokenID(ctx, payload.ID)
+	if currentResult.Error != nil && !errors.Is(currentResult.Error, query.ErrRefreshTokenNotFound) {
+		return nil, currentResult.Error
+	}
+
+	if current, ok := currentResult.Result.(storage.RefreshToken); ok {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID == current.FamilyID
+		}
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession revokes a session of the user, its tokens can not be used anymore.
+func (s *UserServiceImpl) RevokeSession(ctx context.Context, id string, payload *token.Payload) error {
+	user, err := s.FindUserByEmail(ctx, payload.Email)
+	if err != nil {
+		return err
+	}
+
+	result := <-s.SessionQuery.FindByID(ctx, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, query.ErrSessionNotFound) {
+			return NewErr(ErrNotFoundCode, "session")
+		}
+		return result.Error
+	}
+
+	session, ok := result.Result.(storage.Session)
+	if !ok {
+		return errors.New("helper type assertion")
+	}
+
+	// the sessions of the other users are not disclosed.
+	if session.UserID != user.ID {
+		return NewErr(ErrNotFoundCode, "session")
+	}
+
+	err = <-s.RefreshTokenCommand.RevokeFamily(ctx, session.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SeeSession records the activity of the session the access token belongs to.
+func (s *UserServiceImpl) SeeSession(ctx context.Context, accessTokenID, ip string) error {
+	return <-s.SessionCommand.See(ctx, accessTokenID, ip, time.Now())
+}
+
+// startSession records a new login of the user from the client and issues its tokens.
+// the method is how the user has proven who they are, it is kept in the audit trail.
+func (s *UserServiceImpl) startSession(ctx context.Context, userAuth storage.UserAuth, client storage.Client, method string) (storage.UserAuth, error) {
+	session := entity.CreateSession(userAuth.UserID, client.UserAgent, client.IP, time.Now().Add(config.Env.RefreshTokenDuration))
+	err := <-s.SessionCommand.Save(ctx, session)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	err = s.audit(ctx, userAuth.UserID, entity.AuditLoginSucceeded, userAuth.UserID, client, map[string]string{
+		"method":     method,
+		"session_id": session.ID,
+	})
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	return s.issueTokens(ctx, userAuth, session.ID)
+}
+
+// issueTokens sets a new access and refresh token on the userAuth,
+// the refresh token is persisted in the family, an empty familyID starts a new one.
+func (s *UserServiceImpl) issueTokens(ctx context.Context, userAuth storage.UserAuth, familyID string) (storage.UserAuth, error) {
+	accessToken, accessPayload, err := s.TokenMaker.CreateToken(userAuth.Email, userAuth.Role, config.Env.AccessTokenDuration)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	refreshToken, payload, err := s.TokenMaker.CreateRefreshToken(userAuth.Email, config.Env.RefreshTokenDuration)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	stored := entity.CreateRefreshToken(payload.ID, familyID, userAuth.UserID, payload.ExpiredAt)
+	stored.IssueWith(accessPayload.ID, accessPayload.ExpiredAt)
+
+	err = <-s.RefreshTokenCommand.Save(ctx, stored)
+	if err != nil {
+		return storage.UserAuth{}, err
+	}
+
+	userAuth.AccessToken = accessToken
+	userAuth.RefreshToken = refreshToken
 	return userAuth, nil
 }
 
 // ResetPassword resets user's password.
-func (s *UserServiceImpl) ResetPassword(ctx context.Context, code, newPassword, newConfirmPassword string) error {
+func (s *UserServiceImpl) ResetPassword(ctx context.Context, email, code, newPassword, newConfirmPassword string, client storage.Client) error {
 	if len(code) != 10 {
 		return NewErr(ErrInvalidCode, "code")
 	}
 	if !NumberRegex.MatchString(code) {
 		return NewErr(ErrInvalidCode, "code")
 	}
+	if email == "" {
+		return NewErr(ErrEmailEmptyCode, "email")
+	}
 	if newPassword == "" {
 		return NewErr(ErrPasswordEmptyCode, "new password")
 	}
@@ -177,46 +594,105 @@ func (s *UserServiceImpl) ResetPassword(ctx context.Context, code, newPassword,
 	}
 
 	// business logic
-	tokenResult := <-s.TokenQuery.Find(code)
-	if tokenResult.Error != nil {
-		return NewErr(ErrInvalidCode, "code")
+	err := s.verifyCode(email, code, "reset-password")
+	if err != nil {
+		return err
 	}
 
-	codeVerification, _ := tokenResult.Result.([]byte)
-	if codeVerification == nil {
-		return NewErr(ErrInvalidCode, "code")
+	user, err := s.FindUserByEmail(ctx, email)
+	if err != nil {
+		return err
 	}
 
-	extract := strings.Split(string(codeVerification), "|")
-	if extract[0] != code {
-		return NewErr(ErrInvalidCode, "code")
+	err = user.ResetPassword(newPassword)
+	if err != nil {
+		return err
 	}
 
-	user, err := s.FindUserByEmail(ctx, extract[1])
+	err = <-s.UserCommand.UpdatePassword(ctx, &user)
 	if err != nil {
 		return err
 	}
 
-	err = user.ResetPassword(newPassword)
+	// the new password lifts a lock left by guesses at the old one.
+	err = s.unlock(email)
 	if err != nil {
 		return err
 	}
 
-	err = <-s.UserCommand.UpdatePassword(ctx, &user)
+	// whoever knew the old password is logged out.
+	err = <-s.RefreshTokenCommand.RevokeUser(ctx, user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return s.audit(ctx, user.ID, entity.AuditPasswordReset, user.ID, client, nil)
+}
+
+// verifyCode checks the code sent to the email for kind and uses it up.
+// after maxCodeAttempts wrong codes the code is invalidated.
+func (s *UserServiceImpl) verifyCode(email, code, kind string) error {
+	tokenResult := <-s.TokenQuery.Find(codeKey(kind, email))
+	if tokenResult.Error != nil {
+		return NewErr(ErrInvalidCode, "code")
+	}
+
+	codeVerification, _ := tokenResult.Result.([]byte)
+	if codeVerification == nil {
+		return NewErr(ErrInvalidCode, "code")
+	}
+
+	extract := strings.Split(string(codeVerification), "|")
+	if extract[0] != code {
+		attempts, err := s.increment(attemptsKey(kind, email), verificationCodeDuration)
+		if err != nil {
+			return err
+		}
+		if attempts.Count < maxCodeAttempts {
+			return NewErr(ErrInvalidCode, "code")
+		}
+
+		err = <-s.TokenCommand.Delete(codeKey(kind, email))
+		if err != nil {
+			return err
+		}
+		return NewErr(ErrCodeAttemptsExceededCode, "code")
+	}
+
+	err := <-s.TokenCommand.Delete(codeKey(kind, email))
 	if err != nil {
 		return err
 	}
 
-	err = <-s.TokenCommand.Delete(code)
+	return <-s.TokenCommand.Delete(attemptsKey(kind, email))
+}
+
+// limit counts a request against key, once there have been more than max
+// requests in window the request is refused until the window is over.
+func (s *UserServiceImpl) limit(key string, max int64, window time.Duration, fieldName string) error {
+	counter, err := s.increment(key, window)
 	if err != nil {
 		return err
 	}
+	if counter.Count > max {
+		return NewRetryErr(ErrTooManyRequestsCode, fieldName, counter.ResetIn)
+	}
 
 	return nil
 }
 
+// codeKey is the key of the verification code sent to the email for kind.
+func codeKey(kind, email string) string {
+	return "code:" + kind + ":" + strings.ToLower(email)
+}
+
+// attemptsKey is the key of the wrong attempts at the code sent to the email for kind.
+func attemptsKey(kind, email string) string {
+	return "attempts:" + kind + ":" + strings.ToLower(email)
+}
+
 // ChangePassword changes user's password.
-func (s *UserServiceImpl) ChangePassword(ctx context.Context, oldPassword, newPassword, newConfirmPassword string, email string) error {
+func (s *UserServiceImpl) ChangePassword(ctx context.Context, oldPassword, newPassword, newConfirmPassword string, email string, client storage.Client) error {
 	if newPassword == "" {
 		return NewErr(ErrPasswordEmptyCode, "new password")
 	}
@@ -248,11 +724,19 @@ func (s *UserServiceImpl) ChangePassword(ctx context.Context, oldPassword, newPa
 		return err
 	}
 
-	return nil
+	return s.audit(ctx, user.ID, entity.AuditPasswordChanged, user.ID, client, nil)
 }
 
 // ChangeBio changes user's bio.
-func (s *UserServiceImpl) ChangeBio(ctx context.Context, bio, email string) error {
+// the bio is plain text and stored as typed, a bio with html in it is refused.
+func (s *UserServiceImpl) ChangeBio(ctx context.Context, bio, email string, client storage.Client) error {
+	if markdown.HasMarkup(bio) {
+		return NewErr(ErrMarkupNotAllowedCode, "bio")
+	}
+	if utf8.RuneCountInString(bio) > 50 {
+		return NewErr(ErrFieldTooLongCode, "bio")
+	}
+
 	user, err := s.FindUserByEmail(ctx, email)
 	if err != nil {
 		return err
@@ -264,11 +748,11 @@ func (s *UserServiceImpl) ChangeBio(ctx context.Context, bio, email string) erro
 		return err
 	}
 
-	return nil
+	return s.audit(ctx, user.ID, entity.AuditProfileUpdated, user.ID, client, map[string]string{"field": "bio"})
 }
 
 // ChangeImage changes user's image.
-func (s *UserServiceImpl) ChangeImage(ctx context.Context, file *multipart.FileHeader, email string) error {
+func (s *UserServiceImpl) ChangeImage(ctx context.Context, file *multipart.FileHeader, email string, client storage.Client) error {
 	result := <-UploadImage(ctx, s.CloudStorage, file, email)
 	if result.Error != nil {
 		return result.Error
@@ -284,5 +768,19 @@ func (s *UserServiceImpl) ChangeImage(ctx context.Context, file *multipart.FileH
 	if err != nil {
 		return err
 	}
-	return nil
+
+	return s.audit(ctx, user.ID, entity.AuditProfileUpdated, user.ID, client, map[string]string{"field": "image"})
+}
+
+// toSessionEntity converts the stored session to the entity.
+func toSessionEntity(session storage.Session) entity.Session {
+	return entity.Session{
+		ID:          session.ID,
+		UserID:      session.UserID,
+		UserAgent:   session.UserAgent,
+		IP:          session.IP,
+		CreatedDate: session.CreatedDate,
+		LastSeenAt:  session.LastSeenAt,
+		ExpiredAt:   session.ExpiredAt,
+	}
 }
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/SemmiDev/blog/internal/common/mail"
+	"github.com/SemmiDev/blog/internal/common/memory"
+	. "github.com/SemmiDev/blog/internal/user/helper"
+	queryMemory "github.com/SemmiDev/blog/internal/user/query/memory"
+	commandMemory "github.com/SemmiDev/blog/internal/user/repository/memory"
+	"github.com/SemmiDev/blog/internal/user/storage"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testEmail = "jane@example.com"
+
+// outbox is a mail.Mailer that keeps the messages instead of sending them.
+type outbox struct {
+	messages []mail.Message
+}
+
+func (o *outbox) Send(ctx context.Context, message mail.Message) error {
+	o.messages = append(o.messages, message)
+	return nil
+}
+
+// newTestService returns a UserServiceImpl whose codes and counters are kept in memory.
+func newTestService(t *testing.T) (*UserServiceImpl, *outbox) {
+	store := memory.New()
+	t.Cleanup(func() { store.Close() })
+
+	mailer := &outbox{}
+	return &UserServiceImpl{
+		TokenQuery:   queryMemory.NewTokenQueryMemory(store),
+		TokenCommand: commandMemory.NewTokenCommandMemory(store),
+		Mailer:       mailer,
+	}, mailer
+}
+
+// sentCode returns the code last sent to the email for kind.
+func sentCode(t *testing.T, s *UserServiceImpl, kind, email string) string {
+	result := <-s.TokenQuery.Find(codeKey(kind, email))
+	if result.Error != nil {
+		t.Fatalf("no %s code for %s: %v", kind, email, result.Error)
+	}
+	return strings.Split(string(result.Result.([]byte)), "|")[0]
+}
+
+// wrongCode returns a code of the right format that is not code.
+func wrongCode(code string) string {
+	if code == "0000000000" {
+		return "1111111111"
+	}
+	return "0000000000"
+}
+
+// errCode returns the error code of an Err or RetryErr, or "" for any other error.
+func errCode(err error) string {
+	var retryErr RetryErr
+	if errors.As(err, &retryErr) {
+		return retryErr.ErrorCode
+	}
+	var theError Err
+	if errors.As(err, &theError) {
+		return theError.ErrorCode
+	}
+	return ""
+}
+
+func TestVerifyCode(t *testing.T) {
+	invalid := NewErr(ErrInvalidCode, "code").ErrorCode
+	exceeded := NewErr(ErrCodeAttemptsExceededCode, "code").ErrorCode
+
+	tests := []struct {
+		name       string
+		wrongCodes int
+		// want is the error code of the right code given after the wrong ones.
+		want string
+	}{
+		{name: "right code", wrongCodes: 0, want: ""},
+		{name: "right code after wrong ones", wrongCodes: maxCodeAttempts - 1, want: ""},
+		{name: "max attempts invalidates the code", wrongCodes: maxCodeAttempts, want: invalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+			err := s.SendVerificationCode(context.Background(), testEmail, "registration", storage.Client{IP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("SendVerificationCode() error = %v", err)
+			}
+			code := sentCode(t, s, "registration", testEmail)
+
+			for i := 1; i <= tt.wrongCodes; i++ {
+				want := invalid
+				if i == maxCodeAttempts {
+					want = exceeded
+				}
+				err := s.verifyCode(testEmail, wrongCode(code), "registration")
+				if got := errCode(err); got != want {
+					t.Fatalf("wrong code %d: error code = %q, want %q (%v)", i, got, want, err)
+				}
+			}
+
+			err = s.verifyCode(testEmail, code, "registration")
+			if got := errCode(err); got != tt.want {
+				t.Fatalf("right code: error code = %q, want %q (%v)", got, tt.want, err)
+			}
+			if tt.want != "" {
+				return
+			}
+
+			// the code is used up.
+			err = s.verifyCode(testEmail, code, "registration")
+			if got := errCode(err); got != invalid {
+				t.Errorf("reused code: error code = %q, want %q", got, invalid)
+			}
+		})
+	}
+}
+
+func TestVerifyCodeKind(t *testing.T) {
+	s, _ := newTestService(t)
+	err := s.SendVerificationCode(context.Background(), testEmail, "reset-password", storage.Client{IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("SendVerificationCode() error = %v", err)
+	}
+	code := sentCode(t, s, "reset-password", testEmail)
+
+	// a code only verifies the kind it has been sent for.
+	err = s.verifyCode(testEmail, code, "delete-account")
+	if got, want := errCode(err), NewErr(ErrInvalidCode, "code").ErrorCode; got != want {
+		t.Errorf("error code = %q, want %q", got, want)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		max      int64
+		requests int
+		refused  bool
+	}{
+		{name: "under the limit", max: 3, requests: 2, refused: false},
+		{name: "at the limit", max: 3, requests: 3, refused: false},
+		{name: "over the limit", max: 3, requests: 4, refused: true},
+		{name: "cooldown", max: 1, requests: 2, refused: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, _ := newTestService(t)
+
+			var err error
+			for i := 0; i < tt.requests; i++ {
+				err = s.limit("limit:key", tt.max, time.Minute, "email")
+			}
+
+			if !tt.refused {
+				if err != nil {
+					t.Fatalf("limit() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var retryErr RetryErr
+			if !errors.As(err, &retryErr) {
+				t.Fatalf("limit() error = %v, want a RetryErr", err)
+			}
+			if retryErr.ErrorCode != NewErr(ErrTooManyRequestsCode, "email").ErrorCode {
+				t.Errorf("error code = %q, want too many requests", retryErr.ErrorCode)
+			}
+			if retryErr.RetryAfter <= 0 || retryErr.RetryAfter > time.Minute {
+				t.Errorf("RetryAfter = %v, want within the window", retryErr.RetryAfter)
+			}
+		})
+	}
+}
+
+func TestSendVerificationCodeCooldown(t *testing.T) {
+	s, mailer := newTestService(t)
+	client := storage.Client{IP: "127.0.0.1"}
+
+	err := s.SendVerificationCode(context.Background(), testEmail, "unlock", client)
+	if err != nil {
+		t.Fatalf("first send: error = %v", err)
+	}
+
+	err = s.SendVerificationCode(context.Background(), testEmail, "unlock", client)
+	var retryErr RetryErr
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("second send: error = %v, want a RetryErr", err)
+	}
+	if retryErr.FieldName != "email" {
+		t.Errorf("FieldName = %q, want email", retryErr.FieldName)
+	}
+	if retryErr.RetryAfter <= 0 || retryErr.RetryAfter > sendCooldown {
+		t.Errorf("RetryAfter = %v, want within %v", retryErr.RetryAfter, sendCooldown)
+	}
+
+	if len(mailer.messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(mailer.messages))
+	}
+	if code := sentCode(t, s, "unlock", testEmail); !strings.Contains(mailer.messages[0].Text, code) {
+		t.Errorf("message does not contain the code %s", code)
+	}
+}
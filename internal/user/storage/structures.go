@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"github.com/SemmiDev/blog/internal/user/rbac"
 	"time"
 )
 
@@ -15,13 +16,182 @@ type User struct {
 	Image       string
 	CreatedDate time.Time
 	LastUpdated time.Time
+	// MFASecret is the encrypted totp secret, MFAEnabledAt is set once
+	// the secret has been confirmed with a code.
+	MFASecret    []byte
+	MFAEnabledAt *time.Time
+	MFALastStep  int64
+	Role         rbac.Role
+	// SuspendedAt is set while an admin has suspended the user,
+	// PasswordResetRequired while an admin has forced a password reset.
+	SuspendedAt           *time.Time
+	PasswordResetRequired bool
+	// DeletionScheduledAt is when the account is deleted, unless the user cancels it.
+	DeletionScheduledAt *time.Time
+}
+
+// Account will be used as response for the users managed by the admins.
+type Account struct {
+	ID                    string     `json:"id"`
+	Name                  string     `json:"name"`
+	Nickname              string     `json:"nickname"`
+	Email                 string     `json:"email"`
+	Bio                   string     `json:"bio"`
+	Image                 string     `json:"image"`
+	Role                  rbac.Role  `json:"role"`
+	MFAEnabled            bool       `json:"mfa_enabled"`
+	SuspendedAt           *time.Time `json:"suspended_at"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	DeletionScheduledAt   *time.Time `json:"deletion_scheduled_at"`
+	CreatedDate           time.Time  `json:"created_at"`
+}
+
+// AccountList will be used as response for a page of users.
+type AccountList struct {
+	Accounts []Account `json:"accounts"`
+	Page     int       `json:"page"`
+	Limit    int       `json:"limit"`
+	Total    int       `json:"total"`
 }
 
 // UserAuth it will be used as response for authentication.
 type UserAuth struct {
-	UserID      string `json:"user_id"`
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	NickName    string `json:"nickname"`
-	AccessToken string `json:"access_token"`
+	UserID       string    `json:"user_id"`
+	Name         string    `json:"name"`
+	Email        string    `json:"email"`
+	NickName     string    `json:"nickname"`
+	Role         rbac.Role `json:"role"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	// MFAToken is returned instead of the tokens when the user has enabled
+	// two-factor authentication, it is exchanged at /auth/mfa/verify.
+	MFAToken string `json:"mfa_token,omitempty"`
+}
+
+// MFAEnrollment will be used as response for enrolling in two-factor authentication.
+type MFAEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+// RefreshToken is a persisted refresh token.
+// UsedAt is set once it has been exchanged for a new pair of tokens.
+type RefreshToken struct {
+	ID            string
+	FamilyID      string
+	UserID        string
+	AccessTokenID string
+	ExpiredAt     time.Time
+	UsedAt        *time.Time
+	RevokedAt     *time.Time
+	CreatedDate   time.Time
+}
+
+// Client is the device a request comes from.
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// Session will be used as response for the active sessions of a user.
+// Current is set on the session the request has been made from.
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      string    `json:"-"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	CreatedDate time.Time `json:"created_at"`
+	LastSeenAt  time.Time `json:"last_seen_at"`
+	ExpiredAt   time.Time `json:"expired_at"`
+	Current     bool      `json:"current"`
+}
+
+// Identity is the subject of an OpenID Connect provider linked to a user,
+// Email is the current email of the user.
+type Identity struct {
+	Provider    string
+	Subject     string
+	UserID      string
+	Email       string
+	CreatedDate time.Time
+}
+
+// APIKey will be used as response for the api keys of a user.
+// Prefix is the start of the key, the key itself is only shown when created.
+type APIKey struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Email       string     `json:"-"`
+	Role        rbac.Role  `json:"-"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiredAt   *time.Time `json:"expired_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedDate time.Time  `json:"created_at"`
+}
+
+// NewAPIKey will be used as response for creating an api key.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// AuditEvent will be used as response for the events of the audit trail,
+// the emails are empty when there is no actor or target, or it has been deleted.
+type AuditEvent struct {
+	ID          string            `json:"id"`
+	ActorID     string            `json:"actor_id"`
+	ActorEmail  string            `json:"actor_email"`
+	Action      string            `json:"action"`
+	TargetID    string            `json:"target_id"`
+	TargetEmail string            `json:"target_email"`
+	IP          string            `json:"ip"`
+	UserAgent   string            `json:"user_agent"`
+	Metadata    map[string]string `json:"metadata"`
+	CreatedDate time.Time         `json:"created_at"`
+}
+
+// AuditEventList will be used as response for a page of the audit trail.
+type AuditEventList struct {
+	Events []AuditEvent `json:"events"`
+	Page   int          `json:"page"`
+	Limit  int          `json:"limit"`
+	Total  int          `json:"total"`
+}
+
+// ExportedPost will be used as the posts of the data export of a user.
+type ExportedPost struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Slug        string    `json:"slug"`
+	Excerpt     string    `json:"excerpt"`
+	Content     string    `json:"content"`
+	Language    string    `json:"language"`
+	Status      string    `json:"status"`
+	Tags        []string  `json:"tags"`
+	Categories  []string  `json:"categories"`
+	PublishedAt time.Time `json:"published_at"`
+	CreatedDate time.Time `json:"created_at"`
+	LastUpdated time.Time `json:"updated_at"`
+}
+
+// ExportedComment will be used as the comments of the data export of a user.
+type ExportedComment struct {
+	ID          string    `json:"id"`
+	PostID      string    `json:"post_id"`
+	PostSlug    string    `json:"post_slug"`
+	ParentID    string    `json:"parent_id"`
+	Content     string    `json:"content"`
+	Status      string    `json:"status"`
+	CreatedDate time.Time `json:"created_at"`
+	LastUpdated time.Time `json:"updated_at"`
+}
+
+// ExportedFavorite will be used as the favorites of the data export of a user.
+type ExportedFavorite struct {
+	PostID      string    `json:"post_id"`
+	PostSlug    string    `json:"post_slug"`
+	PostTitle   string    `json:"post_title"`
+	CreatedDate time.Time `json:"created_at"`
 }
@@ -0,0 +1,70 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every api key, it tells them apart from the paseto tokens.
+	APIKeyPrefix = "blog_pat_"
+	// apiKeyDisplayLength is how much of an api key is kept in clear to recognize it.
+	apiKeyDisplayLength = len(APIKeyPrefix) + 6
+)
+
+// Scope is what an api key can be used for.
+type Scope string
+
+const (
+	// ScopePosts allows writing the posts of the user.
+	ScopePosts Scope = "posts"
+	// ScopeComments allows writing the comments of the user.
+	ScopeComments Scope = "comments"
+)
+
+// IsScope reports whether the scope exists.
+func IsScope(scope string) bool {
+	switch Scope(scope) {
+	case ScopePosts, ScopeComments:
+		return true
+	default:
+		return false
+	}
+}
+
+// GenerateAPIKey returns a new random api key, the part of it that can be
+// displayed and its hash, which is the only thing to be stored.
+func GenerateAPIKey() (key, display string, hash []byte, err error) {
+	b := make([]byte, 32)
+	_, err = rand.Read(b)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return key, key[:apiKeyDisplayLength], HashAPIKey(key), nil
+}
+
+// HashAPIKey hashes the api key, the keys are random enough for a fast hash.
+func HashAPIKey(key string) []byte {
+	sum := sha256.Sum256([]byte(key))
+	return sum[:]
+}
+
+// NewAPIKeyPayload creates the payload of an api key, so that the handlers
+// see the same payload whether the request has been made with a token or a key.
+// ExpiredAt is zero for a key that does not expire.
+func NewAPIKeyPayload(id, email string, role rbac.Role, scopes []Scope, issuedAt, expiredAt time.Time) *Payload {
+	return &Payload{
+		ID:        id,
+		Type:      TypeAPIKey,
+		Email:     email,
+		Role:      role,
+		Scopes:    scopes,
+		IssuedAt:  issuedAt,
+		ExpiredAt: expiredAt,
+	}
+}
@@ -1,11 +1,26 @@
 package token
 
-import "time"
+import (
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	"time"
+)
 
 // Maker is a token maker.
 type Maker interface {
-	// CreateToken Make creates a new token.
-	CreateToken(email string, duration time.Duration) (string, error)
+	// CreateToken Make creates a new token, its payload is returned
+	// so that the token can be revoked by its id.
+	// the role of the user is embedded in the token.
+	CreateToken(email string, role rbac.Role, duration time.Duration) (string, *Payload, error)
+	// CreateRefreshToken creates a new refresh token, its payload is returned
+	// so that the token can be persisted by its id.
+	CreateRefreshToken(email string, duration time.Duration) (string, *Payload, error)
+	// CreateMFAPendingToken creates a new token for a user who has given
+	// their password, but still has to give their totp code.
+	CreateMFAPendingToken(email string, duration time.Duration) (string, *Payload, error)
 	// VerifyToken Verify verifies a token.
 	VerifyToken(token string) (*Payload, error)
+	// VerifyRefreshToken verifies a refresh token.
+	VerifyRefreshToken(token string) (*Payload, error)
+	// VerifyMFAPendingToken verifies an mfa pending token.
+	VerifyMFAPendingToken(token string) (*Payload, error)
 }
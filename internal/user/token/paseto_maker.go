@@ -2,6 +2,7 @@ package token
 
 import (
 	"fmt"
+	"github.com/SemmiDev/blog/internal/user/rbac"
 	"github.com/o1egl/paseto"
 	"golang.org/x/crypto/chacha20poly1305"
 	"time"
@@ -26,16 +27,85 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 }
 
 // CreateToken it will be generated token with the given payload.
-func (maker *PasetoMaker) CreateToken(email string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(email, duration)
+func (maker *PasetoMaker) CreateToken(email string, role rbac.Role, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(email, role, duration)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
-	return maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+
+	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+// CreateRefreshToken it will be generated refresh token with the given payload.
+func (maker *PasetoMaker) CreateRefreshToken(email string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewRefreshPayload(email, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
+}
+
+// CreateMFAPendingToken it will be generated mfa pending token with the given payload.
+func (maker *PasetoMaker) CreateMFAPendingToken(email string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewMFAPendingPayload(email, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := maker.paseto.Encrypt(maker.symmetricKey, payload, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, payload, nil
 }
 
 // VerifyToken it will be verified the given token.
+// only access tokens are accepted.
 func (maker *PasetoMaker) VerifyToken(token string) (*Payload, error) {
+	payload, err := maker.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if !payload.IsAccess() {
+		return nil, ErrInvalidToken
+	}
+	return payload, nil
+}
+
+// VerifyRefreshToken it will be verified the given refresh token.
+func (maker *PasetoMaker) VerifyRefreshToken(token string) (*Payload, error) {
+	payload, err := maker.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if !payload.IsRefresh() {
+		return nil, ErrInvalidToken
+	}
+	return payload, nil
+}
+
+// VerifyMFAPendingToken it will be verified the given mfa pending token.
+func (maker *PasetoMaker) VerifyMFAPendingToken(token string) (*Payload, error) {
+	payload, err := maker.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	if payload.Type != TypeMFAPending {
+		return nil, ErrInvalidToken
+	}
+	return payload, nil
+}
+
+func (maker *PasetoMaker) verify(token string) (*Payload, error) {
 	payload := &Payload{}
 
 	err := maker.paseto.Decrypt(token, maker.symmetricKey, payload, nil)
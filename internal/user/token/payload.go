@@ -2,6 +2,7 @@ package token
 
 import (
 	"errors"
+	"github.com/SemmiDev/blog/internal/user/rbac"
 	"github.com/google/uuid"
 	"time"
 )
@@ -11,19 +12,61 @@ var (
 	ErrExpiredToken = errors.New("token has expired")
 )
 
+// Type tells what a token can be used for.
+type Type string
+
+const (
+	// TypeAccess tokens authorize the requests, tokens issued
+	// before the type has been introduced have an empty type.
+	TypeAccess Type = "access"
+	// TypeRefresh tokens are only exchanged for a new pair of tokens.
+	TypeRefresh Type = "refresh"
+	// TypeMFAPending tokens prove the password of a user with two-factor
+	// authentication, they are only exchanged along with a totp code.
+	TypeMFAPending Type = "mfa_pending"
+	// TypeAPIKey payloads are the ones of api keys, they are not paseto tokens.
+	TypeAPIKey Type = "api_key"
+)
+
 // Payload represents the token payload.
 type Payload struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
+	ID    string `json:"id"`
+	Type  Type   `json:"type,omitempty"`
+	Email string `json:"email"`
+	// Role is the role of the user when the token has been issued,
+	// tokens issued before the roles have been introduced have none.
+	Role      rbac.Role `json:"role,omitempty"`
 	IssuedAt  time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
+	// Scopes limit what an api key can be used for, tokens have none.
+	Scopes []Scope `json:"scopes,omitempty"`
 }
 
 // NewPayload creates a new payload.
-func NewPayload(email string, duration time.Duration) (*Payload, error) {
+func NewPayload(email string, role rbac.Role, duration time.Duration) (*Payload, error) {
+	payload, err := newPayload(TypeAccess, email, duration)
+	if err != nil {
+		return nil, err
+	}
+	payload.Role = role
+	return payload, nil
+}
+
+// NewRefreshPayload creates a new payload for a refresh token.
+func NewRefreshPayload(email string, duration time.Duration) (*Payload, error) {
+	return newPayload(TypeRefresh, email, duration)
+}
+
+// NewMFAPendingPayload creates a new payload for an mfa pending token.
+func NewMFAPendingPayload(email string, duration time.Duration) (*Payload, error) {
+	return newPayload(TypeMFAPending, email, duration)
+}
+
+func newPayload(tokenType Type, email string, duration time.Duration) (*Payload, error) {
 	tokenID := uuid.NewString()
 	payload := &Payload{
 		ID:        tokenID,
+		Type:      tokenType,
 		Email:     email,
 		IssuedAt:  time.Now(),
 		ExpiredAt: time.Now().Add(duration),
@@ -31,6 +74,36 @@ func NewPayload(email string, duration time.Duration) (*Payload, error) {
 	return payload, nil
 }
 
+// IsAccess reports whether the payload belongs to an access token.
+func (payload *Payload) IsAccess() bool {
+	return payload.Type == TypeAccess || payload.Type == ""
+}
+
+// IsRefresh reports whether the payload belongs to a refresh token.
+func (payload *Payload) IsRefresh() bool {
+	return payload.Type == TypeRefresh
+}
+
+// IsAPIKey reports whether the payload belongs to an api key.
+func (payload *Payload) IsAPIKey() bool {
+	return payload.Type == TypeAPIKey
+}
+
+// Can reports whether the role of the payload has the permission.
+func (payload *Payload) Can(permission rbac.Permission) bool {
+	return payload.Role.Can(permission)
+}
+
+// HasScope reports whether the payload has been given the scope.
+func (payload *Payload) HasScope(scope Scope) bool {
+	for _, s := range payload.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // Valid checks if the payload is valid.
 func (payload *Payload) Valid() error {
 	if time.Now().After(payload.ExpiredAt) {
@@ -2,19 +2,41 @@ package main
 
 import (
 	"context"
+	"fmt"
 	. "github.com/SemmiDev/blog/config"
+	commentserver "github.com/SemmiDev/blog/internal/comment/server"
 	zerolog "github.com/SemmiDev/blog/internal/common/logger"
+	"github.com/SemmiDev/blog/internal/common/migration"
+	feedserver "github.com/SemmiDev/blog/internal/feed/server"
+	postserver "github.com/SemmiDev/blog/internal/post/server"
+	userEntity "github.com/SemmiDev/blog/internal/user/entity"
+	userQueryPostgresql "github.com/SemmiDev/blog/internal/user/query/postgresql"
+	"github.com/SemmiDev/blog/internal/user/rbac"
+	userCommandPostgresql "github.com/SemmiDev/blog/internal/user/repository/postgresql"
 	userserver "github.com/SemmiDev/blog/internal/user/server"
+	userStorage "github.com/SemmiDev/blog/internal/user/storage"
 	"github.com/SemmiDev/blog/internal/user/token"
+	"github.com/SemmiDev/blog/sql/migrations"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	fLog "github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/jackc/pgx/v4/pgxpool"
 	log "github.com/sirupsen/logrus"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 )
 
+const (
+	migrateUsage = "usage: blog migrate up | down [steps] | status"
+	roleUsage    = "usage: blog role <email> admin | editor | author | reader"
+)
+
 func main() {
 	// set up the configurations.
 	LoadConfig(".")
@@ -29,22 +51,65 @@ func main() {
 	}
 	defer dbPool.Close()
 
+	// run the migrations instead of the server when asked to.
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err != nil {
+			log.Fatal(err)
+		}
+		migrate(dbPool, os.Args[2:])
+		return
+	}
+
+	// change the role of a user instead of running the server when asked to,
+	// it is how the first admin is made.
+	if len(os.Args) > 1 && os.Args[1] == "role" {
+		if err != nil {
+			log.Fatal(err)
+		}
+		changeRole(dbPool, os.Args[2:])
+		return
+	}
+
 	// set up the token manager.
 	tokenMaker, err := token.NewPasetoMaker(Env.TokenSymmetricKey)
 	if err != nil {
 		zerolog.Log.Error().Interface("token maker", err).Send()
 	}
 
-	// set up the auth server.
-	authServer, err := userserver.NewAuthServer(dbPool, tokenMaker)
+	// set up the verification code store.
+	verificationStore, err := userserver.NewVerificationStore(dbPool)
 	if err != nil {
-		zerolog.Log.Error().Interface("auth server", err).Send()
+		zerolog.Log.Error().Interface("verification store", err).Send()
 	}
 
+	// set up the user service, shared by the auth and the user servers.
+	userService, err := userserver.NewUserService(dbPool, tokenMaker, verificationStore)
+	if err != nil {
+		zerolog.Log.Error().Interface("user service", err).Send()
+	}
+
+	// set up the auth server.
+	authServer := userserver.NewAuthServer(userService)
+
 	// set up the user server.
-	userServer, err := userserver.NewUserServer(dbPool, tokenMaker)
+	userServer := userserver.NewUserServer(userService, tokenMaker)
+
+	// set up the post server.
+	postServer, err := postserver.NewPostServer(dbPool)
+	if err != nil {
+		zerolog.Log.Error().Interface("post server", err).Send()
+	}
+
+	// set up the comment server.
+	commentServer, err := commentserver.NewCommentServer(dbPool)
 	if err != nil {
-		zerolog.Log.Error().Interface("user server", err).Send()
+		zerolog.Log.Error().Interface("comment server", err).Send()
+	}
+
+	// set up the feed server.
+	feedServer, err := feedserver.NewFeedServer(dbPool)
+	if err != nil {
+		zerolog.Log.Error().Interface("feed server", err).Send()
 	}
 
 	// set up the fiber app.
@@ -62,12 +127,148 @@ func main() {
 
 	// set up the auth routes.
 	authGroup := app.Group("/auth")
-	authServer.Mount(authGroup)
+	authServer.Mount(authGroup, userServer.AuthMiddleware())
 
 	// set up the user routes.
 	userGroup := app.Group("/users")
 	userServer.Mount(userGroup)
 
+	// set up the user management and audit routes of the admins.
+	adminGroup := app.Group("/admin")
+	userServer.MountAdmin(adminGroup)
+
+	// set up the post routes.
+	postGroup := app.Group("/posts")
+	postServer.Mount(postGroup, userServer.AuthMiddleware(token.ScopePosts))
+
+	// set up the feed routes.
+	feedServer.Mount(app)
+
+	// set up the tag and category routes.
+	tagGroup := app.Group("/tags")
+	postServer.MountTags(tagGroup)
+	categoryGroup := app.Group("/categories")
+	postServer.MountCategories(categoryGroup)
+
+	// set up the search routes.
+	searchGroup := app.Group("/search")
+	postServer.MountSearch(searchGroup)
+
+	// set up the comment routes.
+	commentGroup := app.Group("/comments")
+	commentServer.Mount(commentGroup, userServer.AuthMiddleware(token.ScopeComments))
+
+	// stop on interrupt, once the requests in flight have been answered.
+	go func() {
+		stop := make(chan os.Signal, 1)
+		signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+		<-stop
+		_ = app.Shutdown()
+	}()
+
 	// start the app on the server address port.
-	log.Fatal(app.Listen(Env.ServerAddress))
+	err = app.Listen(Env.ServerAddress)
+	userServer.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// migrate applies, rolls back or lists the embedded migrations.
+func migrate(db *pgxpool.Pool, args []string) {
+	if len(args) == 0 {
+		log.Fatal(migrateUsage)
+	}
+
+	migrator, err := migration.New(db, migrations.FS)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migration")
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				log.Fatal(migrateUsage)
+			}
+		}
+
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			fmt.Printf("rolled back %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	case "status":
+		statuses, err := migrator.Status(ctx)
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.Applied {
+				appliedAt = status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, appliedAt)
+		}
+		w.Flush()
+
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatal(migrateUsage)
+	}
+}
+
+// changeRole changes the role of the user with the email.
+func changeRole(db *pgxpool.Pool, args []string) {
+	if len(args) != 2 || !rbac.IsRole(args[1]) {
+		log.Fatal(roleUsage)
+	}
+
+	ctx := context.Background()
+
+	result := <-userQueryPostgresql.NewUserQueryPostgresql(db).FindByEmail(ctx, strings.ToLower(args[0]))
+	if result.Error != nil {
+		log.Fatal(result.Error)
+	}
+
+	user, ok := result.Result.(userStorage.User)
+	if !ok {
+		log.Fatal("helper type assertion")
+	}
+
+	err := <-userCommandPostgresql.NewUserCommandPostgresql(db).UpdateRole(ctx, &userEntity.User{ID: user.ID, Role: rbac.Role(args[1])})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// the change has no actor, it has been made from the command line.
+	event := userEntity.CreateAuditEvent("", userEntity.AuditRoleChanged, user.ID, "", "cli", map[string]string{
+		"from": string(user.Role),
+		"to":   args[1],
+	})
+	err = <-userCommandPostgresql.NewAuditEventCommandPostgresql(db).Save(ctx, event)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("%s is now %s, from their next token on\n", user.Email, args[1])
 }
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS users
+(
+    id                 VARCHAR(255)       NOT NULL PRIMARY KEY,
+    name               VARCHAR(50)        NOT NULL,
+    nickname           VARCHAR(50)        NOT NULL,
+    email              VARCHAR(50) UNIQUE NOT NULL,
+    password           BYTEA              NOT NULL,
+    bio                VARCHAR(50)        DEFAULT '',
+    image              VARCHAR(255)       NOT NULL DEFAULT 'user-default-image.png',
+    created_at         TIMESTAMP          NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS users_nickname_idx ON users (nickname);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS posts
+(
+    id           VARCHAR(255)        NOT NULL PRIMARY KEY,
+    author_id    VARCHAR(255)        NOT NULL,
+    title        VARCHAR(100)        NOT NULL,
+    slug         VARCHAR(100) UNIQUE NOT NULL,
+    excerpt      VARCHAR(100)        NOT NULL,
+    content      TEXT                NOT NULL,
+    language     REGCONFIG           NOT NULL DEFAULT 'english',
+    status       VARCHAR(20)         NOT NULL DEFAULT 'draft',
+    is_published BOOLEAN             NOT NULL DEFAULT FALSE,
+    published_at TIMESTAMP           NOT NULL DEFAULT NOW(),
+    created_at   TIMESTAMP           NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMP           NOT NULL DEFAULT NOW(),
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector(language, title), 'A') ||
+        setweight(to_tsvector(language, excerpt), 'B') ||
+        setweight(to_tsvector(language, content), 'C')
+    ) STORED,
+    FOREIGN KEY (author_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS posts_title_idx ON posts (title);
+CREATE INDEX IF NOT EXISTS posts_author_id_idx ON posts (author_id);
+CREATE INDEX IF NOT EXISTS posts_status_published_at_idx ON posts (status, published_at);
+CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING GIN (search_vector);
+
+CREATE TABLE IF NOT EXISTS post_slugs
+(
+    slug       VARCHAR(100) NOT NULL PRIMARY KEY,
+    post_id    VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS tags
+(
+    id   VARCHAR(255)        NOT NULL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    slug VARCHAR(255) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS posts_tags
+(
+    post_id VARCHAR(255) NOT NULL,
+    tag_id  VARCHAR(255) NOT NULL,
+    PRIMARY KEY (post_id, tag_id),
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS categories
+(
+    id   VARCHAR(255)        NOT NULL PRIMARY KEY,
+    name VARCHAR(255) UNIQUE NOT NULL,
+    slug VARCHAR(255) UNIQUE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS posts_categories
+(
+    post_id     VARCHAR(255) NOT NULL,
+    category_id VARCHAR(255) NOT NULL,
+    PRIMARY KEY (post_id, category_id),
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (category_id) REFERENCES categories (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS posts_tags_tag_id_idx ON posts_tags (tag_id);
+CREATE INDEX IF NOT EXISTS posts_categories_category_id_idx ON posts_categories (category_id);
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS comments
+(
+    id         VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id    VARCHAR(255) NOT NULL,
+    post_id    VARCHAR(255) NOT NULL,
+    parent_id  VARCHAR(255),
+    content    TEXT         NOT NULL,
+    status     VARCHAR(20)  NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (post_id) REFERENCES posts (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    FOREIGN KEY (parent_id) REFERENCES comments (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS comments_post_id_status_idx ON comments (post_id, status);
+CREATE INDEX IF NOT EXISTS comments_parent_id_idx ON comments (parent_id);
@@ -0,0 +1,14 @@
+CREATE TABLE refresh_tokens
+(
+    id         VARCHAR(255) NOT NULL PRIMARY KEY,
+    family_id  VARCHAR(255) NOT NULL,
+    user_id    VARCHAR(255) NOT NULL,
+    expired_at TIMESTAMP    NOT NULL,
+    used_at    TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX ON refresh_tokens (family_id);
+CREATE INDEX ON refresh_tokens (user_id);
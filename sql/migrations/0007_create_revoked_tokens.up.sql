@@ -0,0 +1,13 @@
+ALTER TABLE refresh_tokens
+    ADD COLUMN access_token_id   VARCHAR(255),
+    ADD COLUMN access_expired_at TIMESTAMP;
+
+CREATE INDEX ON refresh_tokens (access_token_id);
+
+CREATE TABLE revoked_tokens
+(
+    id         VARCHAR(255) NOT NULL PRIMARY KEY,
+    expired_at TIMESTAMP    NOT NULL
+);
+
+CREATE INDEX ON revoked_tokens (expired_at);
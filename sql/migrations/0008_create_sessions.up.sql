@@ -0,0 +1,14 @@
+CREATE TABLE sessions
+(
+    id           VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id      VARCHAR(255) NOT NULL,
+    user_agent   VARCHAR(255) NOT NULL DEFAULT '',
+    ip           VARCHAR(45)  NOT NULL DEFAULT '',
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP    NOT NULL DEFAULT NOW(),
+    expired_at   TIMESTAMP    NOT NULL,
+    revoked_at   TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX ON sessions (user_id);
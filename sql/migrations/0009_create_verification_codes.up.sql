@@ -0,0 +1,8 @@
+CREATE TABLE verification_codes
+(
+    key        VARCHAR(255) NOT NULL PRIMARY KEY,
+    value      BYTEA        NOT NULL,
+    expired_at TIMESTAMP
+);
+
+CREATE INDEX ON verification_codes (expired_at);
@@ -0,0 +1,13 @@
+ALTER TABLE users
+    ADD COLUMN mfa_secret     BYTEA,
+    ADD COLUMN mfa_enabled_at TIMESTAMP,
+    ADD COLUMN mfa_last_step  BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE mfa_recovery_codes
+(
+    user_id   VARCHAR(255) NOT NULL,
+    code_hash BYTEA        NOT NULL,
+    used_at   TIMESTAMP,
+    PRIMARY KEY (user_id, code_hash),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
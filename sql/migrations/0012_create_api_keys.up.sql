@@ -0,0 +1,15 @@
+CREATE TABLE api_keys
+(
+    id           VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_id      VARCHAR(255) NOT NULL,
+    name         VARCHAR(100) NOT NULL,
+    prefix       VARCHAR(20)  NOT NULL,
+    key_hash     BYTEA        NOT NULL UNIQUE,
+    scopes       TEXT[]       NOT NULL,
+    expired_at   TIMESTAMP,
+    last_used_at TIMESTAMP,
+    created_at   TIMESTAMP    NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+
+CREATE INDEX ON api_keys (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'author'
+        CONSTRAINT users_role_check CHECK (role IN ('admin', 'editor', 'author', 'reader'));
@@ -0,0 +1,16 @@
+-- the events outlive the users they are about, so they have no foreign keys.
+CREATE TABLE audit_events
+(
+    id         VARCHAR(255) NOT NULL PRIMARY KEY,
+    actor_id   VARCHAR(255),
+    action     VARCHAR(50)  NOT NULL,
+    target_id  VARCHAR(255),
+    ip         VARCHAR(45)  NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    metadata   JSONB        NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX ON audit_events (actor_id, created_at);
+CREATE INDEX ON audit_events (target_id, created_at);
+CREATE INDEX ON audit_events (created_at);
@@ -0,0 +1,4 @@
+ALTER TABLE users
+    ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX ON users (deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
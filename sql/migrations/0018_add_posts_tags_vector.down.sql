@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS posts_search_tags_vector_idx;
+CREATE INDEX IF NOT EXISTS posts_search_vector_idx ON posts USING GIN (search_vector);
+
+DROP TRIGGER IF EXISTS posts_refresh_tags_vector ON posts;
+DROP TRIGGER IF EXISTS tags_refresh_tags_vector ON tags;
+DROP TRIGGER IF EXISTS posts_tags_refresh_tags_vector ON posts_tags;
+
+DROP FUNCTION IF EXISTS posts_tags_vector_on_posts();
+DROP FUNCTION IF EXISTS posts_tags_vector_on_tags();
+DROP FUNCTION IF EXISTS posts_tags_vector_on_posts_tags();
+DROP FUNCTION IF EXISTS posts_tags_vector(VARCHAR, REGCONFIG);
+
+ALTER TABLE posts
+    DROP COLUMN IF EXISTS tags_vector;
@@ -0,0 +1,66 @@
+-- the names of the tags of a post are searched along with its search_vector,
+-- they are kept in tags_vector by the triggers below so that both can be indexed together.
+ALTER TABLE posts
+    ADD COLUMN tags_vector TSVECTOR NOT NULL DEFAULT '';
+
+CREATE FUNCTION posts_tags_vector(VARCHAR, REGCONFIG) RETURNS TSVECTOR AS
+$$
+SELECT setweight(to_tsvector($2, COALESCE(string_agg(tags.name, ' '), '')), 'A')
+FROM posts_tags
+         JOIN tags ON tags.id = posts_tags.tag_id
+WHERE posts_tags.post_id = $1;
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION posts_tags_vector_on_posts_tags() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        UPDATE posts SET tags_vector = posts_tags_vector(id, language) WHERE id = NEW.post_id;
+    END IF;
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        UPDATE posts SET tags_vector = posts_tags_vector(id, language) WHERE id = OLD.post_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION posts_tags_vector_on_tags() RETURNS TRIGGER AS
+$$
+BEGIN
+    UPDATE posts
+    SET tags_vector = posts_tags_vector(id, language)
+    WHERE id IN (SELECT post_id FROM posts_tags WHERE tag_id = NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION posts_tags_vector_on_posts() RETURNS TRIGGER AS
+$$
+BEGIN
+    NEW.tags_vector := posts_tags_vector(NEW.id, NEW.language);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER posts_tags_refresh_tags_vector
+    AFTER INSERT OR UPDATE OR DELETE
+    ON posts_tags
+    FOR EACH ROW
+EXECUTE FUNCTION posts_tags_vector_on_posts_tags();
+
+CREATE TRIGGER tags_refresh_tags_vector
+    AFTER UPDATE OF name
+    ON tags
+    FOR EACH ROW
+EXECUTE FUNCTION posts_tags_vector_on_tags();
+
+CREATE TRIGGER posts_refresh_tags_vector
+    BEFORE UPDATE OF language
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION posts_tags_vector_on_posts();
+
+UPDATE posts SET tags_vector = posts_tags_vector(id, language);
+
+DROP INDEX IF EXISTS posts_search_vector_idx;
+CREATE INDEX IF NOT EXISTS posts_search_tags_vector_idx ON posts USING GIN ((search_vector || tags_vector));
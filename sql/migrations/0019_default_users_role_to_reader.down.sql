@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ALTER COLUMN role SET DEFAULT 'author';
@@ -0,0 +1,4 @@
+-- new users only comment and favorite, an admin makes them authors.
+-- the existing users keep their role.
+ALTER TABLE users
+    ALTER COLUMN role SET DEFAULT 'reader';
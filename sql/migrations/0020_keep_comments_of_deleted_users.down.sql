@@ -0,0 +1,7 @@
+-- the comments of the deleted users can not belong to anyone again.
+DELETE FROM comments WHERE user_id IS NULL;
+
+ALTER TABLE comments
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE CASCADE,
+    ALTER COLUMN user_id SET NOT NULL;
@@ -0,0 +1,6 @@
+-- the comments of a deleted user are emptied and kept, without their user,
+-- so that the replies of the other users stay in their thread.
+ALTER TABLE comments
+    ALTER COLUMN user_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS comments_user_id_fkey,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users (id) ON UPDATE CASCADE ON DELETE SET NULL;
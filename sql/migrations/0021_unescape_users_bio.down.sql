@@ -0,0 +1,2 @@
+-- the bios are kept as typed, escaped again they may not fit in the column anymore.
+SELECT 1;
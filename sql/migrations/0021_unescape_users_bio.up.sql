@@ -0,0 +1,5 @@
+-- the bios used to be stored escaped, they are plain text as typed now.
+UPDATE users
+SET bio = REPLACE(REPLACE(REPLACE(REPLACE(REPLACE(bio,
+    '&#39;', ''''), '&#34;', '"'), '&lt;', '<'), '&gt;', '>'), '&amp;', '&')
+WHERE bio LIKE '%&%';
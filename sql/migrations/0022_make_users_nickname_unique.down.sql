@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP CONSTRAINT IF EXISTS users_nickname_key;
+CREATE INDEX IF NOT EXISTS users_nickname_idx ON users (nickname);
@@ -0,0 +1,12 @@
+-- the nicknames are the addresses of the author feeds, no two users can share one.
+-- the oldest user keeps a shared nickname, the others get the start of their id after it.
+UPDATE users
+SET nickname = LEFT(users.nickname, 41) || '-' || LEFT(users.id, 8)
+FROM (SELECT id, ROW_NUMBER() OVER (PARTITION BY nickname ORDER BY created_at, id) AS rank
+      FROM users) AS duplicates
+WHERE users.id = duplicates.id
+  AND duplicates.rank > 1;
+
+DROP INDEX IF EXISTS users_nickname_idx;
+ALTER TABLE users
+    ADD CONSTRAINT users_nickname_key UNIQUE (nickname);
@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS users_touch_feeds ON users;
+DROP TRIGGER IF EXISTS posts_tags_touch_feeds ON posts_tags;
+DROP TRIGGER IF EXISTS posts_touch_feeds ON posts;
+DROP FUNCTION IF EXISTS feed_changes_on_users();
+DROP FUNCTION IF EXISTS feed_changes_on_posts_tags();
+DROP FUNCTION IF EXISTS feed_changes_on_posts();
+DROP FUNCTION IF EXISTS touch_feed(VARCHAR);
+DROP TABLE IF EXISTS feed_changes;
@@ -0,0 +1,84 @@
+-- feed_changes keeps when each feed last changed, including when a post left it,
+-- so that its Last-Modified moves when a post is unpublished, deleted or untagged.
+-- the feed is '' for the site, 'author:<user id>' or 'tag:<tag id>'.
+-- the rows are kept up to date by the triggers below.
+CREATE TABLE IF NOT EXISTS feed_changes
+(
+    feed       VARCHAR(300) NOT NULL PRIMARY KEY,
+    changed_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE FUNCTION touch_feed(VARCHAR) RETURNS VOID AS
+$$
+INSERT INTO feed_changes (feed, changed_at)
+VALUES ($1, NOW())
+ON CONFLICT (feed) DO UPDATE SET changed_at = NOW();
+$$ LANGUAGE sql;
+
+CREATE FUNCTION feed_changes_on_posts() RETURNS TRIGGER AS
+$$
+BEGIN
+    -- the drafts and the scheduled posts are not in the feeds.
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.status = 'published' THEN
+        PERFORM touch_feed('');
+        PERFORM touch_feed('author:' || OLD.author_id);
+        PERFORM touch_feed('tag:' || tag_id) FROM posts_tags WHERE post_id = OLD.id;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.status = 'published' THEN
+        PERFORM touch_feed('');
+        PERFORM touch_feed('author:' || NEW.author_id);
+        PERFORM touch_feed('tag:' || tag_id) FROM posts_tags WHERE post_id = NEW.id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION feed_changes_on_posts_tags() RETURNS TRIGGER AS
+$$
+BEGIN
+    IF TG_OP IN ('INSERT', 'UPDATE') THEN
+        PERFORM touch_feed('tag:' || NEW.tag_id);
+    END IF;
+    IF TG_OP IN ('DELETE', 'UPDATE') THEN
+        PERFORM touch_feed('tag:' || OLD.tag_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION feed_changes_on_users() RETURNS TRIGGER AS
+$$
+BEGIN
+    -- the name of the author is part of the items.
+    PERFORM touch_feed('');
+    PERFORM touch_feed('author:' || NEW.id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER posts_touch_feeds
+    AFTER INSERT OR UPDATE OR DELETE
+    ON posts
+    FOR EACH ROW
+EXECUTE FUNCTION feed_changes_on_posts();
+
+CREATE TRIGGER posts_tags_touch_feeds
+    AFTER INSERT OR UPDATE OR DELETE
+    ON posts_tags
+    FOR EACH ROW
+EXECUTE FUNCTION feed_changes_on_posts_tags();
+
+CREATE TRIGGER users_touch_feeds
+    AFTER UPDATE OF name, nickname
+    ON users
+    FOR EACH ROW
+EXECUTE FUNCTION feed_changes_on_users();
+
+-- the feeds are considered changed from now on.
+INSERT INTO feed_changes (feed)
+SELECT ''
+UNION
+SELECT 'author:' || id FROM users
+UNION
+SELECT 'tag:' || id FROM tags
+ON CONFLICT (feed) DO NOTHING;
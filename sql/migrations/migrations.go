@@ -0,0 +1,13 @@
+// Package migrations embeds the versioned schema migrations of the blog database.
+//
+// every migration is a pair of files named <version>_<name>.up.sql and
+// <version>_<name>.down.sql, the version is a number that only grows.
+// an applied migration must never be edited, add a new one instead.
+package migrations
+
+import "embed"
+
+// FS contains the migration files.
+//
+//go:embed *.sql
+var FS embed.FS